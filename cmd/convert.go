@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/encoder"
 	"github.com/Enigma-Dark/runes/internal/files"
 	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/logger"
 	"github.com/Enigma-Dark/runes/internal/output"
 	"github.com/Enigma-Dark/runes/internal/replay"
 	"github.com/Enigma-Dark/runes/internal/types"
@@ -19,92 +30,479 @@ var (
 	contractName string
 	testName     string
 	templateName string
+	artifactsDir string
+	targetName   string
+	abiFile      string
+	appendMode   bool
+	outputFormat string
+	medusaTarget string
+
+	campaignLog     string
+	propertyName    string
+	checkProperty   bool
+	dedupe          bool
+	reportFormat    string
+	reportFile      string
+	dedupeThreshold float64
+	parseJobs       int
+
+	corpusMode    bool
+	corpusOutput  string
+	topLongest    int
+	sampleSize    int
+	sampleSeed    int64
+	functionNames []string
+
+	selectMode    string
+	sinceValue    string
+	untilValue    string
+	groupWindow   time.Duration
+	includeGlobs  []string
+	excludeGlobs  []string
+	recursiveScan bool
 )
 
 // convertCmd represents the convert command
 var convertCmd = &cobra.Command{
 	Use:   "convert [reproducer-file-or-directory]",
-	Short: "Convert an Echidna reproducer file to a Foundry test",
-	Long: `Convert an Echidna reproducer .txt file to a Foundry test file.
+	Short: "Convert an Echidna reproducer or Medusa call sequence to a Foundry test",
+	Long: `Convert an Echidna reproducer .txt file or a Medusa call sequence .json file
+to a Foundry test file.
 
-The reproducer file should be a JSON array of transactions generated by Echidna.
-You can provide either a specific .txt file or a directory containing .txt files.
-When a directory is provided, files are grouped by creation time and the newest 
-group is processed, generating one test function per .txt file in that group.
+The input format is detected automatically from the file contents: Echidna
+reproducers are JSON arrays of transactions, Medusa call sequences are the JSON
+files found under call_sequences/immutable and call_sequences/mutable.
+You can provide either a specific file or a directory containing .txt/.json files.
+Use "-" to read a single reproducer from standard input; the generated test is then
+written to standard output unless --output is set. "--output -" writes to standard
+output as well, and progress messages go to standard error in both cases.
+When a directory is provided, files written within --group-window of each other form
+a group and the newest group is processed, generating one test function per file.
+Use --select all, --since/--until, --include/--exclude and --recursive to choose
+other files; the selected files are listed before processing.
+With --append, new test functions are added to an existing output contract instead of
+overwriting it; sequences it already replays are skipped and colliding names get a suffix.
+Tests are named after the property they break, found in the file name or in the Echidna
+output given with --campaign-log, or set with --property. The property is checked at the
+end of the test, e.g. assertTrue(Tester.echidna_solvency()); the failing call of an
+assertion failure is marked with a comment instead.
+With --dedupe, reproducers that replay the same call sequence once numbers, addresses
+and delays are normalized produce a single test; --dedupe-threshold below 1 also collapses
+sequences that only differ in some argument values.
 
 This command will parse the file(s) and generate corresponding Foundry test functions.
 
+With --report json|junit|markdown, the processed files, generated test names, call counts
+and failures are also written in machine-readable form, to --report-file or standard output.
+
+With --format medusa, each sequence is written as a Medusa call sequence named after its
+test to the --output directory (e.g. medusa-corpus/call_sequences/immutable), so
+reproducers can seed a Medusa campaign. Calls carry their ABI encoded data and values;
+pass --abi or --artifacts so tuple arguments can be keyed by field name. Every call is
+sent to --medusa-target, by default the address Medusa deploys the first target contract
+at (0xA647ff3c36cFab592509E13860ab8c4F28781a66); pass --medusa-target "" to keep the
+addresses of the input.
+
+With --corpus, the input is an Echidna corpus directory: the sequences of its coverage and
+reproducers folders are converted into one contract, or one contract per folder with
+--corpus-output split, each test noting its origin. --top, --sample and --function keep
+the output manageable by selecting sequences of each folder.
+
 Example:
   runes convert reproducer.txt --output ReplayTest.t.sol --contract ReplayTest --test testReplay
-  runes convert /path/to/reproducers/ --output ReplayTest.t.sol`,
+  runes convert /path/to/reproducers/ --output ReplayTest.t.sol
+  runes convert /path/to/reproducers/ --since 2h --include '*withdraw*'
+  runes convert corpus/ --recursive --select all
+  runes convert echidna/reproducers --select all --dedupe --dedupe-threshold 0.8
+  runes convert echidna/reproducers --select all --campaign-log echidna.log
+  runes convert reproducer.txt --property echidna_solvency
+  runes convert echidna/reproducers --select all --report junit --report-file reports/runes.xml
+  runes convert echidna/corpus --corpus --corpus-output split --top 20 --function withdraw
+  runes convert reproducer.txt --artifacts out/ --target-contract Tester
+  runes convert echidna/reproducers --select all --format medusa --output medusa-corpus/call_sequences/immutable
+  runes convert /path/to/reproducers/ --output test/Replay.t.sol --append
+  runes convert cache/invariant/failures/Invariants/invariant_solvency --abi out/Tester.sol/Tester.json
+  jq '.[:5]' reproducer.txt | runes convert - > test/Replay.t.sol`,
 	Args: cobra.ExactArgs(1),
 	RunE: runConvert,
 }
 
 func init() {
 	rootCmd.AddCommand(convertCmd)
-	convertCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path or directory (if directory, auto-generates incrementing names like ReplayTest_1.t.sol)")
+	convertCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path or directory (if directory, auto-generates incrementing names like ReplayTest_1.t.sol), or - for stdout")
 	convertCmd.Flags().StringVarP(&contractName, "contract", "c", "", "Contract name (default: [input-name]Replay or ReplayTestN)")
 	convertCmd.Flags().StringVarP(&testName, "test", "t", "", "Test function name (deprecated - auto-generated for groups)")
 	convertCmd.Flags().StringVarP(&templateName, "template", "", "enigmadark", "Template to use: 'basic', 'enigmadark', or path to custom .tmpl file")
+	convertCmd.Flags().StringVar(&artifactsDir, "artifacts", "", "Foundry out directory used to resolve exact function signatures and struct names")
+	convertCmd.Flags().StringVar(&targetName, "target-contract", "", "Contract called by the reproducers, looked up in --artifacts (e.g. Tester or Tester.t.sol:Tester)")
+	convertCmd.Flags().StringVar(&outputFormat, "format", formatFoundry, "Output format: 'foundry' test contract or 'medusa' call sequences")
+	convertCmd.Flags().StringVar(&medusaTarget, "medusa-target", encoder.DefaultMedusaTarget, "Address the calls of --format medusa sequences are sent to, empty to keep those of the input")
+	convertCmd.Flags().BoolVar(&appendMode, "append", false, "Add new test functions to an existing output contract instead of overwriting it")
+	convertCmd.Flags().StringVar(&campaignLog, "campaign-log", "", "Echidna output (text or --format json) used to name tests after the properties they break")
+	convertCmd.Flags().StringVar(&propertyName, "property", "", "Property broken by the reproducers, checked at the end of each test (default: inferred from file names and --campaign-log)")
+	convertCmd.Flags().BoolVar(&checkProperty, "assert", true, "Check the broken property at the end of each test when it is known")
+	convertCmd.Flags().BoolVar(&dedupe, "dedupe", false, "Collapse reproducers that replay the same call sequence into one test")
+	convertCmd.Flags().Float64Var(&dedupeThreshold, "dedupe-threshold", 1, "Minimum fraction of equal argument values for --dedupe to collapse two sequences (1 for exact duplicates)")
+	convertCmd.Flags().StringVar(&reportFormat, "report", "", "Write a processing report: "+strings.Join(logger.ReportFormats(), ", "))
+	convertCmd.Flags().IntVar(&parseJobs, "jobs", 0, "Number of reproducer files parsed in parallel (default: number of CPUs)")
+	convertCmd.Flags().StringVar(&reportFile, "report-file", "", "File the --report is written to (default: standard output)")
+	convertCmd.Flags().StringVar(&abiFile, "abi", "", "JSON ABI or Foundry artifact of the target contract, an alternative to --artifacts")
+	convertCmd.Flags().StringVar(&selectMode, "select", "", "Files to process from a directory: 'newest' group or 'all' (default: all with --since/--until, newest otherwise)")
+	convertCmd.Flags().StringVar(&sinceValue, "since", "", "Only files modified at or after this time (e.g. 2h, 2024-05-01, 2024-05-01 14:00, RFC 3339)")
+	convertCmd.Flags().StringVar(&untilValue, "until", "", "Only files modified at or before this time (same formats as --since)")
+	convertCmd.Flags().DurationVar(&groupWindow, "group-window", files.DefaultGroupWindow, "Largest gap between files written in the same group")
+	convertCmd.Flags().StringSliceVar(&includeGlobs, "include", nil, "Only files whose name or relative path matches one of these glob patterns")
+	convertCmd.Flags().StringSliceVar(&excludeGlobs, "exclude", nil, "Skip files whose name or relative path matches one of these glob patterns")
+	convertCmd.Flags().BoolVarP(&recursiveScan, "recursive", "r", false, "Search subdirectories of the input directory")
+	convertCmd.Flags().BoolVar(&corpusMode, "corpus", false, "Convert the coverage and reproducers folders of an Echidna corpus directory")
+	convertCmd.Flags().StringVar(&corpusOutput, "corpus-output", corpusCombined, "Contracts generated with --corpus: 'combined' or 'split' (one per folder)")
+	convertCmd.Flags().IntVar(&topLongest, "top", 0, "Only convert the N longest sequences (of each corpus folder)")
+	convertCmd.Flags().IntVar(&sampleSize, "sample", 0, "Only convert a random sample of N sequences (of each corpus folder)")
+	convertCmd.Flags().Int64Var(&sampleSeed, "seed", 1, "Seed of --sample, the same seed selects the same sequences")
+	convertCmd.Flags().StringSliceVar(&functionNames, "function", nil, "Only convert sequences calling one of these functions")
 }
 
 // runConvert is the main convert command logic
 func runConvert(cmd *cobra.Command, args []string) error {
 	inputPath := args[0]
 
-	// Discover replay files
-	replayFiles, err := files.DiscoverReplayFiles(inputPath)
+	if dedupeThreshold <= 0 || dedupeThreshold > 1 {
+		return fmt.Errorf("--dedupe-threshold must be greater than 0 and at most 1, got %g", dedupeThreshold)
+	}
+	if parseJobs < 0 {
+		return fmt.Errorf("--jobs must not be negative, got %d", parseJobs)
+	}
+	if topLongest < 0 || sampleSize < 0 {
+		return fmt.Errorf("--top and --sample must not be negative")
+	}
+	if corpusOutput != corpusCombined && corpusOutput != corpusSplit {
+		return fmt.Errorf("unknown corpus output %q (expected %q or %q)", corpusOutput, corpusCombined, corpusSplit)
+	}
+	split := corpusOutput == corpusSplit
+	if split && !corpusMode {
+		return fmt.Errorf("--corpus-output split requires --corpus")
+	}
+	if outputFormat != formatFoundry && outputFormat != formatMedusa {
+		return fmt.Errorf("unknown output format %q (expected %q or %q)", outputFormat, formatFoundry, formatMedusa)
+	}
+	medusa := outputFormat == formatMedusa
+	if medusa && (appendMode || split) {
+		return fmt.Errorf("--format medusa cannot be combined with --append or --corpus-output split")
+	}
+	if medusa && outputFile == "" && inputPath != files.StdinPath {
+		return fmt.Errorf("--format medusa requires --output, the directory the call sequences are written to")
+	}
+
+	writesTestToStdout := outputFile == output.StdoutPath || (inputPath == files.StdinPath && outputFile == "")
+	writesReportToStdout := reportFormat != "" && (reportFile == "" || reportFile == output.StdoutPath)
+	if writesTestToStdout && writesReportToStdout {
+		return fmt.Errorf("--report-file is required when the test is written to standard output")
+	}
+	if reportFormat != "" && !slices.Contains(logger.ReportFormats(), reportFormat) {
+		return fmt.Errorf("unknown report format %q (expected one of: %s)", reportFormat, strings.Join(logger.ReportFormats(), ", "))
+	}
+
+	// Keep stdout clean for the generated code or report when it is written there
+	if writesTestToStdout || writesReportToStdout {
+		defer logger.AvoidStdout()()
+	}
+
+	// Load the target contract ABI if provided
+	contractABI, artifacts, err := loadTarget(artifactsDir, targetName, abiFile)
 	if err != nil {
-		return fmt.Errorf("failed to resolve input files: %w", err)
+		return err
 	}
 
-	// Process files into replay groups
-	allReplays, err := replay.ProcessFiles(replayFiles)
+	// Discover replay files
+	discoverOptions, err := resolveDiscoverOptions()
 	if err != nil {
 		return err
 	}
 
+	var replayFiles []files.FileInfo
+	if corpusMode {
+		replayFiles, err = files.DiscoverCorpus(inputPath, discoverOptions)
+	} else {
+		replayFiles, err = files.DiscoverReplayFiles(inputPath, discoverOptions)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
 	// Resolve output configuration
-	config := resolveOutputConfig(replayFiles, allReplays)
+	config := resolveOutputConfig(replayFiles)
+	config.ABI = contractABI
+	config.Artifacts = artifacts
+	if appendMode && config.OutputFile == output.StdoutPath {
+		return fmt.Errorf("--append requires an output file")
+	}
+	if split && config.OutputFile == output.StdoutPath {
+		return fmt.Errorf("--corpus-output split requires an output file")
+	}
+	targets := []outputTarget{{config: config}}
+	if split {
+		targets = splitTargets(config)
+	}
+
+	// Name tests after the broken properties, with a number taken from ReplayTest_N output files
+	var campaign *replay.Campaign
+	if campaignLog != "" {
+		if campaign, err = replay.LoadCampaign(campaignLog); err != nil {
+			return err
+		}
+	}
+	namer := replay.NewNamer(extractNumberFromFilename(config.OutputFile))
+	if appendMode {
+		for _, target := range targets {
+			existing, err := generator.ContractFunctions(target.config.OutputFile)
+			if err != nil {
+				return err
+			}
+			for _, name := range existing {
+				namer.Reserve(name)
+			}
+		}
+	}
+
+	// Process files into replay groups
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	processLog := logger.NewProcessorLogger()
+	allReplays, err := replay.ProcessFiles(ctx, replayFiles, replay.ProcessOptions{
+		Dedupe:          dedupe,
+		DedupeThreshold: dedupeThreshold,
+		Namer:           namer,
+		Campaign:        campaign,
+		Property:        propertyName,
+		Logger:          processLog,
+		Jobs:            parseJobs,
+		Selection: replay.Selection{
+			Functions: functionNames,
+			Top:       topLongest,
+			Sample:    sampleSize,
+			Seed:      sampleSeed,
+		},
+	})
 
-	// Extract number from output filename and update test names
-	number := extractNumberFromFilename(config.OutputFile)
-	if number != "" {
+	if err == nil {
+		err = generateOutputs(allReplays, targets, contractABI, artifacts, writesTestToStdout, processLog)
+	}
+
+	// The report also covers runs where no file could be converted or generation failed
+	if reportFormat != "" {
+		if reportErr := writeProcessingReport(processLog.GetStats()); reportErr != nil {
+			return reportErr
+		}
+	}
+	return err
+}
+
+// generateOutputs writes the replay groups as the test contracts of the targets, or as Medusa
+// call sequences. The files whose output could not be generated are recorded as failures.
+func generateOutputs(allReplays []types.ReplayGroup, targets []outputTarget, contractABI *abi.ABI, artifacts *abi.ArtifactSet, toStdout bool, processLog *logger.ProcessorLogger) error {
+	split := corpusOutput == corpusSplit
+
+	// Decode raw calldata into function calls and recognize deployed contracts where possible
+	replay.DecodeCalls(allReplays, contractABI, artifacts)
+	if !checkProperty {
 		for i := range allReplays {
-			functionName, _ := replay.GenerateTestFunctionName(allReplays[i].FileName, number, allReplays[i].Calls)
-			allReplays[i].TestName = functionName
+			allReplays[i].Property = ""
 		}
 	}
 
+	if outputFormat == formatMedusa {
+		err := writeMedusaSequences(allReplays, encoder.MedusaOptions{ABI: contractABI, Target: medusaTarget}, toStdout)
+		if err != nil {
+			logGenerationFailure(processLog, err, allReplays)
+		}
+		return err
+	}
+
+	for _, target := range targets {
+		config := target.config
+		config.ReplayGroups = allReplays
+		if split {
+			config.ReplayGroups = replaysOfOrigin(allReplays, target.origin)
+			if len(config.ReplayGroups) == 0 {
+				logger.Info(fmt.Sprintf("No %s sequences selected, %s not generated", target.origin, config.OutputFile), "origin", target.origin)
+				continue
+			}
+		}
+		if err := writeTarget(config); err != nil {
+			logGenerationFailure(processLog, err, config.ReplayGroups)
+			return err
+		}
+	}
+	return nil
+}
+
+// logGenerationFailure records the files whose output could not be generated: the file named
+// by a *generator.GroupError, or else every file of the groups being written
+func logGenerationFailure(processLog *logger.ProcessorLogger, err error, groups []types.ReplayGroup) {
+	var groupErr *generator.GroupError
+	if errors.As(err, &groupErr) {
+		processLog.LogGenerationFailure(groupErr.FileName, groupErr.Err)
+		return
+	}
+	for _, group := range groups {
+		processLog.LogGenerationFailure(group.FileName, err)
+	}
+}
+
+// writeTarget generates a test contract, or merges its tests into the existing one with --append
+func writeTarget(config generator.GenerateConfig) error {
+	// Load the sender to actor mapping from the config file
+	actors, err := generator.NewActorTable(viper.GetStringMapString("actors"))
+	if err != nil {
+		return fmt.Errorf("invalid actors configuration: %w", err)
+	}
+	config.Actors = actors
+
+	// Merge into the existing contract
+	if appendMode {
+		result, err := generator.MergeFoundryTest(config)
+		if err != nil {
+			return fmt.Errorf("failed to append to test file: %w", err)
+		}
+
+		printActorWarnings(actors)
+		printMergeInfo(config, result)
+		return nil
+	}
+
 	// Generate the test file
-	if err := generator.GenerateFoundryTest(config); err != nil {
+	if config.OutputFile == output.StdoutPath {
+		err = generator.Render(os.Stdout, config)
+	} else {
+		err = generator.GenerateFoundryTest(config)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to generate test file: %w", err)
 	}
 
-	printSuccessInfo(config, len(allReplays))
+	printActorWarnings(actors)
+	printSuccessInfo(config, len(config.ReplayGroups))
 	return nil
 }
 
+// writeMedusaSequences writes each replay group as a Medusa call sequence named after its
+// test to the --output directory, or the single group to standard output
+func writeMedusaSequences(groups []types.ReplayGroup, options encoder.MedusaOptions, toStdout bool) error {
+	if toStdout {
+		if len(groups) != 1 {
+			return fmt.Errorf("standard output can only hold a single call sequence, %d were converted", len(groups))
+		}
+		data, err := encoder.MarshalMedusa(groups[0].Calls, options)
+		if err != nil {
+			return &generator.GroupError{FileName: groups[0].FileName, Err: err}
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(outputFile, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, group := range groups {
+		path := filepath.Join(outputFile, group.TestName+".json")
+		if err := encoder.WriteMedusaFile(path, group.Calls, options); err != nil {
+			return &generator.GroupError{FileName: group.FileName, Err: err}
+		}
+		logger.Event(slog.LevelInfo, fmt.Sprintf("  ✓ %s -> %s", filepath.Base(group.FileName), path),
+			"wrote call sequence", "file", group.FileName, "output", path, "calls", len(group.Calls))
+	}
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Successfully wrote %d Medusa call sequences to %s", len(groups), outputFile),
+		"wrote call sequences", "dir", outputFile, "sequences", len(groups))
+	return nil
+}
+
+// resolveDiscoverOptions builds the directory selection options from the command-line flags
+func resolveDiscoverOptions() (files.DiscoverOptions, error) {
+	now := time.Now()
+
+	since, err := files.ParseTime(sinceValue, now)
+	if err != nil {
+		return files.DiscoverOptions{}, fmt.Errorf("invalid --since: %w", err)
+	}
+
+	until, err := files.ParseTime(untilValue, now)
+	if err != nil {
+		return files.DiscoverOptions{}, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	return files.DiscoverOptions{
+		Mode:        selectMode,
+		Since:       since,
+		Until:       until,
+		GroupWindow: groupWindow,
+		Include:     includeGlobs,
+		Exclude:     excludeGlobs,
+		Recursive:   recursiveScan,
+	}, nil
+}
+
+// loadTarget loads the target contract ABI from --abi or from --artifacts, along with
+// all artifacts when --artifacts is set (used to recognize deployed contracts).
+// Both are nil when neither flag is set.
+func loadTarget(artifactsDir, targetName, abiFile string) (*abi.ABI, *abi.ArtifactSet, error) {
+	if abiFile != "" {
+		if artifactsDir != "" {
+			return nil, nil, fmt.Errorf("--abi and --artifacts cannot be used together")
+		}
+		contractABI, err := abi.LoadABIFile(abiFile)
+		return contractABI, nil, err
+	}
+	if artifactsDir == "" {
+		return nil, nil, nil
+	}
+
+	if targetName == "" {
+		return nil, nil, fmt.Errorf("--target-contract is required when --artifacts is set")
+	}
+
+	artifacts, err := abi.LoadArtifacts(artifactsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artifact, err := artifacts.Find(targetName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artifact.ABI, artifacts, nil
+}
+
+// printActorWarnings warns about senders that were missing from the actor mapping
+func printActorWarnings(actors *generator.ActorTable) {
+	for _, actor := range actors.Unmapped() {
+		logger.Warn(fmt.Sprintf("sender %s is not mapped to an actor, generated constant %s (add it to 'actors' in .runes.yaml)", actor.Address, actor.Name),
+			"sender", actor.Address, "actor", actor.Name)
+	}
+}
+
 // printProcessingInfo displays information about files being processed
 func printProcessingInfo(replayFiles []files.FileInfo) {
 	if len(replayFiles) == 1 {
-		fmt.Printf("Processing single file: %s\n", replayFiles[0].Path)
+		logger.Printf("Processing single file: %s\n", replayFiles[0].Path)
 	} else {
-		fmt.Printf("Processing %d files from newest group (created at %s):\n",
+		logger.Printf("Processing %d files from newest group (created at %s):\n",
 			len(replayFiles), replayFiles[0].ModTime.Format("2006-01-02 15:04:05"))
 		for _, file := range replayFiles {
-			fmt.Printf("  - %s\n", filepath.Base(file.Path))
+			logger.Printf("  - %s\n", filepath.Base(file.Path))
 		}
 	}
 }
 
 // resolveOutputConfig determines output file and contract name
-func resolveOutputConfig(replayFiles []files.FileInfo, allReplays []types.ReplayGroup) generator.GenerateConfig {
+func resolveOutputConfig(replayFiles []files.FileInfo) generator.GenerateConfig {
 	isMultiple := len(replayFiles) > 1
 
-	// Resolve output file
+	// Resolve output file (reproducers read from stdin are written to stdout by default)
 	resolvedOutput := outputFile
+	if resolvedOutput == "" && replayFiles[0].Path == files.StdinPath {
+		resolvedOutput = output.StdoutPath
+	}
 	if resolvedOutput == "" {
 		if isMultiple {
 			resolvedOutput = "grouped_replays.t.sol"
@@ -114,7 +512,9 @@ func resolveOutputConfig(replayFiles []files.FileInfo, allReplays []types.Replay
 		}
 	}
 
-	resolvedOutput = output.ResolveOutputPath(resolvedOutput, isMultiple)
+	if resolvedOutput != output.StdoutPath {
+		resolvedOutput = output.ResolveOutputPath(resolvedOutput, isMultiple)
+	}
 
 	// Resolve contract name
 	resolvedContract := contractName
@@ -125,16 +525,119 @@ func resolveOutputConfig(replayFiles []files.FileInfo, allReplays []types.Replay
 	return generator.GenerateConfig{
 		ContractName: resolvedContract,
 		OutputFile:   resolvedOutput,
-		ReplayGroups: allReplays,
 		Template:     templateName,
 	}
 }
 
+// Output formats of the convert command
+const (
+	formatFoundry = "foundry" // Foundry test contract replaying the sequences
+	formatMedusa  = "medusa"  // Medusa call sequences, one file per sequence
+)
+
+// Contracts generated from an Echidna corpus directory
+const (
+	corpusCombined = "combined" // One contract replaying every folder
+	corpusSplit    = "split"    // One contract per folder
+)
+
+// outputTarget is a contract written by a conversion, with the corpus folder it replays
+// when the output is split
+type outputTarget struct {
+	origin string
+	config generator.GenerateConfig
+}
+
+// splitTargets derives one contract per corpus folder from the combined output, e.g.
+// Replay_coverage.t.sol with contract ReplayCoverage
+func splitTargets(config generator.GenerateConfig) []outputTarget {
+	var targets []outputTarget
+	for _, origin := range files.CorpusOrigins {
+		target := config
+		base := strings.TrimSuffix(strings.TrimSuffix(config.OutputFile, ".sol"), ".t")
+		target.OutputFile = base + "_" + origin + strings.TrimPrefix(config.OutputFile, base)
+		target.ContractName = config.ContractName + strings.ToUpper(origin[:1]) + origin[1:]
+		targets = append(targets, outputTarget{origin: origin, config: target})
+	}
+	return targets
+}
+
+// replaysOfOrigin returns the replay groups of sequences from one corpus folder
+func replaysOfOrigin(groups []types.ReplayGroup, origin string) []types.ReplayGroup {
+	var result []types.ReplayGroup
+	for _, group := range groups {
+		if group.Origin == origin {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// writeProcessingReport writes the --report to --report-file or standard output
+func writeProcessingReport(stats logger.ProcessingStats) error {
+	if reportFile == "" || reportFile == output.StdoutPath {
+		return logger.WriteReport(os.Stdout, reportFormat, stats)
+	}
+
+	if dir := filepath.Dir(reportFile); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	file, err := os.Create(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := logger.WriteReport(file, reportFormat, stats); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Wrote %s report to %s", reportFormat, reportFile), "wrote report", "format", reportFormat, "file", reportFile)
+	return nil
+}
+
 // printSuccessInfo displays success information
 func printSuccessInfo(config generator.GenerateConfig, testCount int) {
-	fmt.Printf("Successfully generated Foundry test: %s\n", config.OutputFile)
-	fmt.Printf("Contract name: %s\n", config.ContractName)
-	fmt.Printf("Generated %d test functions\n", testCount)
+	destination := config.OutputFile
+	if destination == output.StdoutPath {
+		destination = "standard output"
+	}
+	logger.Event(slog.LevelInfo, "Successfully generated Foundry test: "+destination, "generated test contract",
+		"file", destination, "contract", config.ContractName, "tests", testCount)
+	logger.Printf("Contract name: %s\n", config.ContractName)
+	logger.Printf("Generated %d test functions\n", testCount)
+}
+
+// printMergeInfo displays what an --append run changed
+func printMergeInfo(config generator.GenerateConfig, result *generator.MergeResult) {
+	if result.Created {
+		logger.Printf("%s did not exist, generated it\n", config.OutputFile)
+	}
+	for _, name := range result.Added {
+		if original, ok := originalName(result.Renamed, name); ok {
+			logger.Printf("  + %s (renamed from %s)\n", name, original)
+		} else {
+			logger.Printf("  + %s\n", name)
+		}
+	}
+	for _, name := range result.Skipped {
+		logger.Printf("  = %s (call sequence already present)\n", name)
+	}
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Appended %d test functions to %s, skipped %d already present", len(result.Added), config.OutputFile, len(result.Skipped)),
+		"appended tests", "file", config.OutputFile, "added", len(result.Added), "skipped", len(result.Skipped))
+}
+
+// originalName finds the name a renamed test function was generated with
+func originalName(renamed map[string]string, name string) (string, bool) {
+	for original, final := range renamed {
+		if final == name {
+			return original, true
+		}
+	}
+	return "", false
 }
 
 // extractNumberFromFilename extracts the number from ReplayTest_X pattern
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/Enigma-Dark/runes/internal/encoder"
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+var (
+	exportTests  []string
+	exportOutput string
+	exportTarget string
+	exportGas    int64
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [test-file]",
+	Short: "Turn replay tests back into Echidna reproducers",
+	Long: `Parse the test functions of a runes-generated Foundry test back into Echidna's
+reproducer JSON format, so a hand-edited replay can seed a new campaign.
+
+Actor changes, delays, function calls with their arguments, raw calls and deployments
+from creation code are exported; the property check at the end of a test is not. Actor
+constants resolve through the contract's own declarations and the 'actors' mapping of
+.runes.yaml. The target, gas limit and gas price recorded in the comments of generated
+tests are kept; tests without them take the target and gas limit from --target and --gas.
+
+Each test is written to [test-name].txt in the output directory, ready to be dropped
+into an Echidna corpus directory.
+
+Example:
+  runes export test/Replay.t.sol --output echidna-corpus/coverage
+  runes export test/Replay.t.sol --test test_replay_1 --output variant.txt
+  runes export test/Replay.t.sol --test test_replay_1 --output -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringSliceVarP(&exportTests, "test", "t", nil, "Test functions to export (default: all test functions)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", ".", "Output directory, a .txt/.json file or - for stdout when exporting a single test")
+	exportCmd.Flags().StringVar(&exportTarget, "target", generator.DefaultExportTarget, "Address the transactions are sent to when the test does not record it")
+	exportCmd.Flags().Int64Var(&exportGas, "gas", generator.DefaultExportGas, "Gas limit of the transactions when the test does not record it")
+}
+
+// runExport is the main export command logic
+func runExport(cmd *cobra.Command, args []string) error {
+	inputPath := args[0]
+
+	// Keep stdout clean for the reproducer when it is written there
+	if exportOutput == "-" {
+		defer logger.AvoidStdout()()
+	}
+
+	source, err := os.ReadFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputPath, err)
+	}
+
+	actors, err := generator.NewActorTable(viper.GetStringMapString("actors"))
+	if err != nil {
+		return fmt.Errorf("invalid actors configuration: %w", err)
+	}
+
+	tests, err := generator.ExportTests(string(source), exportTests, generator.ExportOptions{
+		Actors: actors,
+		Target: exportTarget,
+		Gas:    exportGas,
+	})
+	if err != nil {
+		return err
+	}
+
+	single := exportOutput == "-" || filepath.Ext(exportOutput) == ".txt" || filepath.Ext(exportOutput) == ".json"
+	if single && len(tests) > 1 {
+		return fmt.Errorf("%s can only hold a single test, %d were found (select one with --test)", exportOutput, len(tests))
+	}
+	if !single {
+		if err := os.MkdirAll(exportOutput, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	for _, test := range tests {
+		data, err := encoder.MarshalEchidna(test.Calls)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", test.Name, err)
+		}
+
+		if exportOutput == "-" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+
+		outputPath := exportOutput
+		if !single {
+			outputPath = filepath.Join(exportOutput, test.Name+".txt")
+		}
+		if err := os.WriteFile(outputPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write reproducer %s: %w", outputPath, err)
+		}
+
+		logger.Event(slog.LevelInfo, fmt.Sprintf("  ✓ %s -> %s (%d calls)", test.Name, outputPath, len(test.Calls)),
+			"exported test", "test", test.Name, "file", outputPath, "calls", len(test.Calls))
+	}
+	return nil
+}
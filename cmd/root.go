@@ -2,13 +2,23 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
 )
 
-var cfgFile string
+var (
+	cfgFile   string
+	quiet     bool
+	verbose   bool
+	logFormat string
+	logOutput string
+	logFile   *os.File // Log file opened for --log-output, closed on exit
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -25,25 +35,59 @@ corresponding Solidity test functions that can be executed with forge test.`,
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	if logFile != nil {
+		logFile.Close()
+	}
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initLogging, initConfig)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.runes.yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only log warnings and errors")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Also log debug details, e.g. how each transaction was decoded")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: 'text' or 'json' (one JSON record per line)")
+	rootCmd.PersistentFlags().StringVar(&logOutput, "log-output", "stdout", "Where logs are written: 'stdout', 'stderr' or a file path")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// initLogging applies the logging flags
+func initLogging() {
+	if quiet && verbose {
+		cobra.CheckErr(fmt.Errorf("--quiet and --verbose cannot be used together"))
+	}
+	minimum := slog.LevelInfo
+	switch {
+	case quiet:
+		minimum = slog.LevelWarn
+	case verbose:
+		minimum = slog.LevelDebug
+	}
+	cobra.CheckErr(logger.Configure(minimum, logFormat))
+
+	switch logOutput {
+	case "", "stdout":
+		logger.Output = os.Stdout
+	case "stderr":
+		logger.Output = os.Stderr
+	default:
+		file, err := os.Create(logOutput)
+		cobra.CheckErr(err)
+		logFile = file
+		logger.Output = file
+	}
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
@@ -63,7 +107,7 @@ func initConfig() {
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
+	if err := viper.ReadInConfig(); err == nil && logFormat == logger.FormatText && !quiet {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/Enigma-Dark/runes/internal/encoder"
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/replay"
+	"github.com/Enigma-Dark/runes/internal/shrink"
+)
+
+var (
+	oracleCommand      string
+	buildCommand       string
+	shrinkOutput       string
+	shrinkTestFile     string
+	shrinkContractName string
+	shrinkTestName     string
+	shrinkTemplate     string
+	shrinkArtifactsDir string
+	shrinkTargetName   string
+	shrinkABIFile      string
+	shrinkProperty     string
+	shrinkCampaignLog  string
+)
+
+// shrinkCmd represents the shrink command
+var shrinkCmd = &cobra.Command{
+	Use:   "shrink [reproducer-file]",
+	Short: "Minimize a reproducer while a test oracle still reports the failure",
+	Long: `Minimize a reproducer by removing calls that are not needed to trigger the failure.
+
+Runes performs delta debugging over the call sequence. For every candidate it regenerates
+the replay test and runs the oracle command; the failure is considered reproduced when the
+command exits with a non-zero status. Each candidate is compiled with the build command
+first, so a test that does not compile stops the run instead of counting as a reproduction,
+and the full sequence must reproduce the failure before any call is removed. The placeholders
+{test} and {file} are replaced with the test function name and the generated test file.
+
+The replay test checks the property broken by the reproducer, as convert does, so a broken
+property fails the test like an assertion. The property is inferred from the file name or
+--campaign-log, or set with --property.
+
+Pass --abi or --artifacts with --target-contract, as for convert, when the calls take struct
+arguments or call overloaded functions: the candidate test needs the struct names and exact
+signatures to compile.
+
+The minimized sequence is written back in Echidna's reproducer JSON format, and the replay
+test is left regenerated from it.
+
+Example:
+  runes shrink reproducer.txt --oracle "forge test --mt {test}" --test-file test/Shrink.t.sol
+  runes shrink reproducer.txt --oracle "forge test --mt {test}" --artifacts out/ --target-contract Tester
+  runes shrink reproducer.txt --oracle "forge test --mt {test}" --output minimized.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShrink,
+}
+
+func init() {
+	rootCmd.AddCommand(shrinkCmd)
+	shrinkCmd.Flags().StringVar(&oracleCommand, "oracle", "", "Shell command that exits non-zero while the failure reproduces (required)")
+	shrinkCmd.Flags().StringVar(&buildCommand, "build", "forge build", "Shell command that compiles the candidate test, empty to skip")
+	shrinkCmd.Flags().StringVarP(&shrinkOutput, "output", "o", "", "Minimized reproducer path (default: [input-name]_min.txt)")
+	shrinkCmd.Flags().StringVar(&shrinkTestFile, "test-file", "test/RunesShrink.t.sol", "Replay test file regenerated for every candidate")
+	shrinkCmd.Flags().StringVarP(&shrinkContractName, "contract", "c", "RunesShrink", "Contract name of the candidate test")
+	shrinkCmd.Flags().StringVarP(&shrinkTestName, "test", "t", "test_shrink", "Test function name of the candidate test")
+	shrinkCmd.Flags().StringVarP(&shrinkTemplate, "template", "", "enigmadark", "Template to use: 'basic', 'enigmadark', or path to custom .tmpl file")
+	shrinkCmd.Flags().StringVar(&shrinkArtifactsDir, "artifacts", "", "Foundry out directory used to resolve exact function signatures and struct names")
+	shrinkCmd.Flags().StringVar(&shrinkTargetName, "target-contract", "", "Contract called by the reproducer, looked up in --artifacts")
+	shrinkCmd.Flags().StringVar(&shrinkABIFile, "abi", "", "JSON ABI or Foundry artifact of the target contract, an alternative to --artifacts")
+	shrinkCmd.Flags().StringVar(&shrinkProperty, "property", "", "Property broken by the reproducer, checked at the end of the test (default: inferred from the file name and --campaign-log)")
+	shrinkCmd.Flags().StringVar(&shrinkCampaignLog, "campaign-log", "", "Echidna output (text or --format json) the broken property is looked up in")
+	shrinkCmd.MarkFlagRequired("oracle")
+}
+
+// runShrink is the main shrink command logic
+func runShrink(cmd *cobra.Command, args []string) error {
+	inputPath := args[0]
+
+	calls, err := parser.ParseReproducerFile(inputPath)
+	if err != nil {
+		return err
+	}
+	if len(calls) == 0 {
+		return fmt.Errorf("no valid calls found in %s", inputPath)
+	}
+
+	contractABI, artifacts, err := loadTarget(shrinkArtifactsDir, shrinkTargetName, shrinkABIFile)
+	if err != nil {
+		return err
+	}
+
+	actors, err := generator.NewActorTable(viper.GetStringMapString("actors"))
+	if err != nil {
+		return fmt.Errorf("invalid actors configuration: %w", err)
+	}
+
+	property := shrinkProperty
+	if property == "" {
+		var campaign *replay.Campaign
+		if shrinkCampaignLog != "" {
+			if campaign, err = replay.LoadCampaign(shrinkCampaignLog); err != nil {
+				return err
+			}
+		}
+		property = replay.InferProperty(inputPath, calls, campaign)
+	}
+	if property != "" {
+		logger.Info(fmt.Sprintf("Checking property %s at the end of each candidate", property), "property", property)
+	}
+
+	oracle := &shrink.CommandOracle{
+		Command:  oracleCommand,
+		Build:    buildCommand,
+		TestName: shrinkTestName,
+		Property: property,
+		Config: generator.GenerateConfig{
+			ContractName: shrinkContractName,
+			OutputFile:   shrinkTestFile,
+			Template:     shrinkTemplate,
+			Actors:       actors,
+			ABI:          contractABI,
+			Artifacts:    artifacts,
+		},
+	}
+
+	minimizer := &shrink.Minimizer{
+		Oracle: oracle,
+		OnTest: func(size int, reproduces bool) {
+			status := "passes"
+			if reproduces {
+				status = "reproduces"
+			}
+			logger.Event(slog.LevelInfo, fmt.Sprintf("  - %d calls: %s", size, status), "oracle run", "calls", size, "reproduces", reproduces)
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Shrinking %s (%d calls)...", filepath.Base(inputPath), len(calls)), "shrinking", "file", inputPath, "calls", len(calls))
+	minimized, err := minimizer.Minimize(ctx, calls)
+	if err != nil {
+		return err
+	}
+
+	// Leave the replay test generated from the minimized sequence
+	if _, err := oracle.Reproduces(ctx, minimized); err != nil {
+		return err
+	}
+
+	outputPath := shrinkOutput
+	if outputPath == "" {
+		base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+		outputPath = base + "_min.txt"
+	}
+
+	if err := encoder.WriteEchidnaFile(outputPath, minimized); err != nil {
+		return err
+	}
+
+	logger.Event(slog.LevelInfo, fmt.Sprintf("\nMinimized %d calls to %d after %d oracle runs", len(calls), len(minimized), minimizer.Runs()),
+		"minimized reproducer", "calls", len(calls), "minimized", len(minimized), "runs", minimizer.Runs(), "file", outputPath, "test", shrinkTestFile)
+	logger.Printf("Minimized reproducer: %s\n", outputPath)
+	logger.Printf("Replay test: %s\n", shrinkTestFile)
+	return nil
+}
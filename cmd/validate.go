@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Enigma-Dark/runes/internal/files"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/validate"
+)
+
+var (
+	validateFormat    string
+	validateRecursive bool
+)
+
+// validateReport is the machine-readable output of the validate command
+type validateReport struct {
+	Files       int                   `json:"files"`
+	Errors      int                   `json:"errors"`
+	Warnings    int                   `json:"warnings"`
+	Diagnostics []validate.Diagnostic `json:"diagnostics"`
+}
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [reproducer-file-or-directory]",
+	Short: "Check reproducer files against the schema of their format",
+	Long: `Check reproducer files and report every problem with its JSON path and
+source position, e.g.
+
+  reproducer.txt:12:24: error: [3].call.contents[1][0].contents[1]: 300 is out of range for uint8
+
+Echidna reproducers are checked against the full transaction schema: call tags, ABI
+value tags and their contents, integer ranges, byte lengths, array element types,
+addresses, delays and the other transaction fields. Other formats are checked by
+parsing them. Unknown fields are reported as warnings.
+
+All reproducer files of a directory are checked. The command exits with a non-zero
+status when an error is found, which makes it usable in CI.
+
+Example:
+  runes validate reproducer.txt
+  runes validate echidna/reproducers --recursive
+  runes validate echidna/reproducers --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format: 'text' or 'json'")
+	validateCmd.Flags().BoolVarP(&validateRecursive, "recursive", "r", false, "Search subdirectories of the input directory")
+}
+
+// runValidate is the main validate command logic
+func runValidate(cmd *cobra.Command, args []string) error {
+	if validateFormat != "text" && validateFormat != "json" {
+		return fmt.Errorf("unknown format %q (expected 'text' or 'json')", validateFormat)
+	}
+
+	// Diagnostics go to stdout, progress messages to stderr
+	defer logger.AvoidStdout()()
+
+	reproducers, err := files.DiscoverReplayFiles(args[0], files.DiscoverOptions{
+		Mode:      files.ModeAll,
+		Recursive: validateRecursive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve input files: %w", err)
+	}
+
+	report := validateReport{Diagnostics: []validate.Diagnostic{}}
+	for _, file := range reproducers {
+		name, data, err := readReproducer(file.Path)
+		if err != nil {
+			return err
+		}
+
+		for _, diagnostic := range validate.Validate(name, data) {
+			if diagnostic.Severity == validate.SeverityError {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+			report.Diagnostics = append(report.Diagnostics, diagnostic)
+		}
+		report.Files++
+	}
+
+	if validateFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else {
+		for _, diagnostic := range report.Diagnostics {
+			fmt.Println(diagnostic)
+		}
+		fmt.Printf("Checked %d files: %d errors, %d warnings\n", report.Files, report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found %d errors in %d files", report.Errors, report.Files)
+	}
+	return nil
+}
+
+// readReproducer reads a reproducer file, or standard input for files.StdinPath
+func readReproducer(path string) (string, []byte, error) {
+	if path == files.StdinPath {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		return "<stdin>", data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return path, data, nil
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/files"
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/output"
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/replay"
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/watch"
+)
+
+var (
+	watchOutput       string
+	watchContractName string
+	watchTemplate     string
+	watchDebounce     time.Duration
+	watchArtifactsDir string
+	watchTargetName   string
+	watchABIFile      string
+	watchProperty     string
+	watchCampaignLog  string
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [reproducers-directory]",
+	Short: "Append replay tests to a contract whenever reproducers are added or changed",
+	Long: `Watch a directory of reproducers (e.g. Echidna's corpus/reproducers) and keep a
+replay contract up to date during a fuzzing campaign.
+
+Reproducers already in the directory are converted at startup. Every new file appends a
+test function to the contract; a rewritten file (e.g. while Echidna shrinks it) updates
+its existing test function instead of adding another one. Tests are merged into the
+contract as with 'convert --append': setUp, helpers, manual edits and the tests of
+earlier sessions are kept, and sequences already in the contract are skipped. Bursts of
+writes are debounced, so a file that is rewritten many times in a row produces a single
+update.
+
+Tests check the property their reproducer breaks, as with convert: it is inferred from the
+file name or --campaign-log, which is read again for every update, or set with --property.
+
+Example:
+  runes watch echidna/reproducers --output test/replays/ReplayWatch.t.sol
+  runes watch echidna/reproducers --debounce 5s --template basic`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVarP(&watchOutput, "output", "o", "ReplayWatch.t.sol", "Replay contract the tests are appended to")
+	watchCmd.Flags().StringVarP(&watchContractName, "contract", "c", "", "Contract name (default: derived from the output file name)")
+	watchCmd.Flags().StringVarP(&watchTemplate, "template", "", "enigmadark", "Template to use: 'basic', 'enigmadark', or path to custom .tmpl file")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", watch.DefaultDebounce, "Quiet period after the last write before the contract is regenerated")
+	watchCmd.Flags().StringVar(&watchArtifactsDir, "artifacts", "", "Foundry out directory used to resolve exact function signatures and struct names")
+	watchCmd.Flags().StringVar(&watchTargetName, "target-contract", "", "Contract called by the reproducers, looked up in --artifacts")
+	watchCmd.Flags().StringVar(&watchABIFile, "abi", "", "JSON ABI or Foundry artifact of the target contract, an alternative to --artifacts")
+	watchCmd.Flags().StringVar(&watchProperty, "property", "", "Property broken by the reproducers, checked at the end of each test (default: inferred from file names and --campaign-log)")
+	watchCmd.Flags().StringVar(&watchCampaignLog, "campaign-log", "", "Echidna output (text or --format json) used to name tests after the properties they break")
+}
+
+// runWatch is the main watch command logic
+func runWatch(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("path does not exist: %s", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+
+	contractABI, artifacts, err := loadTarget(watchArtifactsDir, watchTargetName, watchABIFile)
+	if err != nil {
+		return err
+	}
+
+	resolvedContract := watchContractName
+	if resolvedContract == "" {
+		resolvedContract = output.GenerateContractName(watchOutput, "ReplayTest")
+	}
+
+	// Tests already in the contract keep their names
+	collection := watch.NewCollection()
+	collection.Property = watchProperty
+	if watchCampaignLog != "" {
+		if collection.Campaign, err = replay.LoadCampaign(watchCampaignLog); err != nil {
+			return err
+		}
+	}
+	declared, err := generator.ContractFunctions(watchOutput)
+	if err != nil {
+		return err
+	}
+	for _, name := range declared {
+		collection.Reserve(name)
+	}
+
+	update := func(groups []types.ReplayGroup, updated []string) error {
+		actors, err := generator.NewActorTable(viper.GetStringMapString("actors"))
+		if err != nil {
+			return fmt.Errorf("invalid actors configuration: %w", err)
+		}
+
+		config := generator.GenerateConfig{
+			ContractName: resolvedContract,
+			OutputFile:   watchOutput,
+			ReplayGroups: groups,
+			Template:     watchTemplate,
+			Actors:       actors,
+			ABI:          contractABI,
+			Artifacts:    artifacts,
+		}
+		result, err := generator.UpdateFoundryTest(config, updated)
+		if err != nil {
+			return fmt.Errorf("failed to update test file: %w", err)
+		}
+
+		printActorWarnings(actors)
+		logger.Event(slog.LevelInfo, fmt.Sprintf("Updated %s (%d tests added, %d updated, %d already present)",
+			watchOutput, len(result.Added), len(result.Updated), len(result.Skipped)),
+			"updated test contract", "file", watchOutput, "added", len(result.Added), "updated", len(result.Updated), "skipped", len(result.Skipped))
+		return nil
+	}
+
+	// Convert the reproducers that are already there
+	existing, err := listReproducerFiles(dir)
+	if err != nil {
+		return err
+	}
+	if groups, updated := addReproducers(collection, existing, contractABI, artifacts); len(groups) > 0 {
+		if err := update(groups, updated); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watcher := &watch.Watcher{
+		Dir:      dir,
+		Debounce: watchDebounce,
+		OnError: func(err error) {
+			logger.Warn(err.Error())
+		},
+	}
+
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Watching %s for reproducers (press Ctrl+C to stop)...", dir), "watching", "dir", dir)
+	return watcher.Run(ctx, func(paths []string) {
+		// The campaign keeps logging failed properties while it runs
+		if watchCampaignLog != "" {
+			if campaign, err := replay.LoadCampaign(watchCampaignLog); err != nil {
+				logger.Warn(err.Error())
+			} else {
+				collection.Campaign = campaign
+			}
+		}
+		groups, updated := addReproducers(collection, paths, contractABI, artifacts)
+		if len(groups) == 0 {
+			return
+		}
+		if err := update(groups, updated); err != nil {
+			logger.Error(err.Error())
+		}
+	})
+}
+
+// addReproducers parses reproducer files into the collection and returns the replay groups
+// that were added or changed, along with the test names of the changed ones
+func addReproducers(collection *watch.Collection, paths []string, contractABI *abi.ABI, artifacts *abi.ArtifactSet) ([]types.ReplayGroup, []string) {
+	var groups []types.ReplayGroup
+	var updated []string
+	for _, path := range paths {
+		calls, err := parser.ParseReproducerFile(path)
+		if err != nil {
+			skipReproducer(path, err)
+			continue
+		}
+		if len(calls) == 0 {
+			skipReproducer(path, fmt.Errorf("no valid calls found"))
+			continue
+		}
+
+		calls, _ = parser.DecodeRawCalls(calls, contractABI)
+		calls, _ = parser.DecodeCreations(calls, artifacts)
+
+		_, known := collection.TestName(path)
+		changed, err := collection.Update(path, calls)
+		if err != nil {
+			skipReproducer(path, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		group, _ := collection.Group(path)
+		name := group.TestName
+		groups = append(groups, group)
+		verb := "Added"
+		if known {
+			verb = "Updated"
+			updated = append(updated, name)
+		}
+		logger.Event(slog.LevelInfo, fmt.Sprintf("- %s %s: %s (%d calls)", verb, filepath.Base(path), name, len(calls)),
+			strings.ToLower(verb)+" reproducer", "file", path, "test", name, "calls", len(calls))
+		if raw := parser.CountCalls(calls, types.CallKindRaw); raw > 0 {
+			message := fmt.Sprintf("%d calls replayed as raw calldata", raw)
+			logger.Event(slog.LevelWarn, "  Warning: "+message, message, "file", path)
+		}
+		if skipped := parser.CountCalls(calls, types.CallKindUnsupported); skipped > 0 {
+			message := fmt.Sprintf("%d transactions cannot be replayed and are left as comments", skipped)
+			logger.Event(slog.LevelWarn, "  Warning: "+message, message, "file", path)
+		}
+	}
+	return groups, updated
+}
+
+// skipReproducer reports a reproducer file that cannot be added to the collection
+func skipReproducer(path string, err error) {
+	logger.Event(slog.LevelWarn, fmt.Sprintf("- Skipping %s: %v", filepath.Base(path), err), "skipped reproducer", "file", path, "error", err.Error())
+}
+
+// listReproducerFiles returns the reproducer files in a directory, sorted by name
+func listReproducerFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && files.IsReproducerFile(entry.Name()) {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -43,7 +44,7 @@ func TestEndToEnd(t *testing.T) {
 	outputFile := filepath.Join(tmpDir, "TestReplay.t.sol")
 
 	// Step 1: Discover files
-	discoveredFiles, err := files.DiscoverReplayFiles(reproducerFile)
+	discoveredFiles, err := files.DiscoverReplayFiles(reproducerFile, files.DiscoverOptions{})
 	require.NoError(t, err)
 	assert.Len(t, discoveredFiles, 1)
 
@@ -54,7 +55,7 @@ func TestEndToEnd(t *testing.T) {
 	assert.Equal(t, "deposit", calls[0].FunctionName)
 
 	// Step 3: Process into replay groups
-	replayGroups, err := replay.ProcessFiles(discoveredFiles)
+	replayGroups, err := replay.ProcessFiles(context.Background(), discoveredFiles, replay.ProcessOptions{})
 	require.NoError(t, err)
 	assert.Len(t, replayGroups, 1)
 
@@ -83,7 +84,7 @@ func TestEndToEnd(t *testing.T) {
 // TestErrorHandling tests basic error scenarios
 func TestErrorHandling(t *testing.T) {
 	// Test with non-existent file
-	_, err := files.DiscoverReplayFiles("/does/not/exist")
+	_, err := files.DiscoverReplayFiles("/does/not/exist", files.DiscoverOptions{})
 	assert.Error(t, err)
 
 	// Test with invalid JSON
@@ -0,0 +1,166 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Argument represents a function input or output in a JSON ABI
+type Argument struct {
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	InternalType string     `json:"internalType,omitempty"`
+	Components   []Argument `json:"components,omitempty"`
+}
+
+// Entry represents a single item of a JSON ABI
+type Entry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name"`
+	Inputs          []Argument `json:"inputs"`
+	Outputs         []Argument `json:"outputs"`
+	StateMutability string     `json:"stateMutability"`
+}
+
+// Method represents a callable function (or the constructor) of a contract
+type Method struct {
+	Name            string
+	Inputs          []Argument
+	Outputs         []Argument
+	StateMutability string
+}
+
+// ABI holds the functions and constructor of a contract
+type ABI struct {
+	Methods     []Method
+	Constructor *Method
+}
+
+// Parse decodes a JSON ABI
+func Parse(data []byte) (*ABI, error) {
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	return FromEntries(entries), nil
+}
+
+// FromEntries builds an ABI from decoded JSON ABI entries
+func FromEntries(entries []Entry) *ABI {
+	result := &ABI{}
+	for _, entry := range entries {
+		method := Method{
+			Name:            entry.Name,
+			Inputs:          entry.Inputs,
+			Outputs:         entry.Outputs,
+			StateMutability: entry.StateMutability,
+		}
+
+		switch entry.Type {
+		case "function":
+			result.Methods = append(result.Methods, method)
+		case "constructor":
+			constructor := method
+			result.Constructor = &constructor
+		}
+	}
+	return result
+}
+
+// Signature returns the canonical signature of a method, e.g. "deposit(uint256,(address,bool))"
+func (m Method) Signature() string {
+	return m.Name + "(" + strings.Join(InputTypes(m.Inputs), ",") + ")"
+}
+
+// IsPayable reports whether the method accepts ETH
+func (m Method) IsPayable() bool {
+	return m.StateMutability == "payable"
+}
+
+// MethodsNamed returns all methods with the given name
+func (a *ABI) MethodsNamed(name string) []Method {
+	var result []Method
+	for _, method := range a.Methods {
+		if method.Name == name {
+			result = append(result, method)
+		}
+	}
+	return result
+}
+
+// Resolve finds the method matching a function name and argument types.
+// The returned flag reports whether the function is overloaded, in which case
+// literals need explicit casts to select the right overload.
+func (a *ABI) Resolve(name string, argTypes []string) (*Method, bool, error) {
+	named := a.MethodsNamed(name)
+	if len(named) == 0 {
+		return nil, false, fmt.Errorf("function %s not found in ABI", name)
+	}
+
+	var sameArity []Method
+	for _, method := range named {
+		if len(method.Inputs) == len(argTypes) {
+			sameArity = append(sameArity, method)
+		}
+	}
+
+	wanted := name + "(" + strings.Join(argTypes, ",") + ")"
+	if len(sameArity) == 0 {
+		return nil, false, fmt.Errorf("no overload of %s takes %d arguments (reproducer calls %s)", name, len(argTypes), wanted)
+	}
+
+	for i := range sameArity {
+		if sameArity[i].Signature() == wanted {
+			return &sameArity[i], len(named) > 1, nil
+		}
+	}
+
+	var declared []string
+	for _, method := range sameArity {
+		declared = append(declared, method.Signature())
+	}
+	return nil, false, fmt.Errorf("reproducer calls %s but the ABI declares %s", wanted, strings.Join(declared, ", "))
+}
+
+// InputTypes returns the canonical types of a list of arguments
+func InputTypes(args []Argument) []string {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		result = append(result, arg.CanonicalType())
+	}
+	return result
+}
+
+// CanonicalType returns the type as used in signatures, expanding tuples to their components
+func (arg Argument) CanonicalType() string {
+	if !strings.HasPrefix(arg.Type, "tuple") {
+		return arg.Type
+	}
+	suffix := strings.TrimPrefix(arg.Type, "tuple")
+	return "(" + strings.Join(InputTypes(arg.Components), ",") + ")" + suffix
+}
+
+// StructName returns the Solidity struct name of a tuple argument (e.g. "Vault.Config"), if known
+func (arg Argument) StructName() string {
+	name, ok := strings.CutPrefix(arg.InternalType, "struct ")
+	if !ok {
+		return ""
+	}
+	if open := strings.Index(name, "["); open >= 0 {
+		name = name[:open]
+	}
+	return name
+}
+
+// Element returns the argument describing the elements of an array argument
+func (arg Argument) Element() Argument {
+	element := arg
+	if open := strings.LastIndex(element.Type, "["); open >= 0 {
+		element.Type = element.Type[:open]
+	}
+	if open := strings.LastIndex(element.InternalType, "["); open >= 0 {
+		element.InternalType = element.InternalType[:open]
+	}
+	return element
+}
@@ -0,0 +1,220 @@
+package abi
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+func TestLoadArtifacts(t *testing.T) {
+	artifacts, err := LoadArtifacts("testdata/out")
+	require.NoError(t, err)
+	require.Len(t, artifacts.Artifacts, 1)
+
+	vault, err := artifacts.Find("Vault")
+	require.NoError(t, err)
+	assert.Equal(t, "Vault.sol", vault.SourcePath)
+	assert.Equal(t, "0x6080604052", vault.Bytecode)
+	assert.Equal(t, "src/Vault.sol", vault.Source)
+	assert.Len(t, vault.ABI.Methods, 5)
+	require.NotNil(t, vault.ABI.Constructor)
+
+	_, err = artifacts.Find("Vault.sol:Vault")
+	assert.NoError(t, err)
+
+	_, err = artifacts.Find("Missing")
+	assert.Error(t, err)
+}
+
+func TestLoadArtifacts_CompilerVersions(t *testing.T) {
+	dir := t.TempDir()
+	artifact := func(path, content string) {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	abiJSON := `"abi": [{"type": "function", "name": "mint", "inputs": [], "outputs": [], "stateMutability": "nonpayable"}]`
+
+	// Projects built with several compilers get one artifact per version
+	artifact("Token.sol/Token.0.8.19.json", `{`+abiJSON+`, "bytecode": {"object": "0x01"}}`)
+	artifact("Token.sol/Token.0.8.20.json", `{`+abiJSON+`, "bytecode": {"object": "0x02"},
+		"metadata": {"settings": {"compilationTarget": {"src/Token.sol": "Token"}}}}`)
+	artifact("Other.sol/Token.json", `{`+abiJSON+`}`)
+	artifact("cache.json", `[1, 2]`)
+
+	artifacts, err := LoadArtifacts(dir)
+	require.NoError(t, err)
+	require.Len(t, artifacts.Artifacts, 3)
+	for _, artifact := range artifacts.Artifacts {
+		assert.Equal(t, "Token", artifact.Name)
+	}
+
+	token, err := artifacts.Find("Token.sol:Token")
+	require.NoError(t, err)
+	assert.Equal(t, "Token.sol", token.SourcePath)
+
+	_, err = artifacts.Find("Token")
+	assert.EqualError(t, err, "contract name Token is ambiguous, use one of: Other.sol:Token, Token.sol:Token")
+}
+
+func TestResolve(t *testing.T) {
+	artifacts, err := LoadArtifacts("testdata/out")
+	require.NoError(t, err)
+	vault, err := artifacts.Find("Vault")
+	require.NoError(t, err)
+
+	method, overloaded, err := vault.ABI.Resolve("deposit", []string{"uint256", "address"})
+	require.NoError(t, err)
+	assert.Equal(t, "deposit(uint256,address)", method.Signature())
+	assert.True(t, overloaded)
+	assert.False(t, method.IsPayable())
+
+	method, overloaded, err = vault.ABI.Resolve("configure", []string{"(uint256,address)[]"})
+	require.NoError(t, err)
+	assert.False(t, overloaded)
+	assert.Equal(t, "Vault.Config", method.Inputs[0].StructName())
+	assert.Equal(t, "tuple", method.Inputs[0].Element().Type)
+
+	// Type mismatch against the ABI
+	_, _, err = vault.ABI.Resolve("setMode", []string{"uint16"})
+	assert.ErrorContains(t, err, "setMode(uint8), setMode(bytes32)")
+
+	// Wrong arity
+	_, _, err = vault.ABI.Resolve("deposit", []string{})
+	assert.Error(t, err)
+
+	// Unknown function
+	_, _, err = vault.ABI.Resolve("withdraw", []string{"uint256"})
+	assert.Error(t, err)
+}
+
+func TestParseSignature(t *testing.T) {
+	name, args, err := ParseSignature("swap((address,uint256),bytes32,uint8)")
+	require.NoError(t, err)
+	assert.Equal(t, "swap", name)
+	assert.Equal(t, []string{"(address,uint256)", "bytes32", "uint8"}, args)
+
+	name, args, err = ParseSignature("pause()")
+	require.NoError(t, err)
+	assert.Equal(t, "pause", name)
+	assert.Empty(t, args)
+
+	_, _, err = ParseSignature("broken(uint256")
+	assert.Error(t, err)
+}
+
+func TestDecodeCall(t *testing.T) {
+	artifacts, err := LoadArtifacts("testdata/out")
+	require.NoError(t, err)
+	vault, err := artifacts.Find("Vault")
+	require.NoError(t, err)
+
+	calldata, err := hex.DecodeString("6e553f65" +
+		"00000000000000000000000000000000000000000000000000000000000003e8" +
+		"000000000000000000000000000000000000000000000000000000000000dead")
+	require.NoError(t, err)
+
+	method, params, err := vault.ABI.DecodeCall(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, "deposit(uint256,address)", method.Signature())
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "uint256", Value: "1000"},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+	}, params)
+
+	_, _, err = vault.ABI.DecodeCall([]byte{0xca, 0xfe, 0xba, 0xbe})
+	assert.ErrorContains(t, err, "0xcafebabe")
+
+	// Truncated arguments
+	_, _, err = vault.ABI.DecodeCall(calldata[:40])
+	assert.Error(t, err)
+}
+
+func TestDecodeArguments_Dynamic(t *testing.T) {
+	data, err := hex.DecodeString(
+		"0000000000000000000000000000000000000000000000000000000000000060" + // offset of the string
+			"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffb" + // int8 -5
+			"00000000000000000000000000000000000000000000000000000000000000a0" + // offset of the array
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"6869000000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000002")
+	require.NoError(t, err)
+
+	params, err := DecodeArguments([]string{"string", "int8", "uint256[]"}, data)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "string", Value: `"hi"`},
+		{Type: "int8", Value: "-5"},
+		{Type: "uint256[]", Elements: []types.ParsedParam{
+			{Type: "uint256", Value: "1"},
+			{Type: "uint256", Value: "2"},
+		}},
+	}, params)
+}
+
+func TestEncodeCall(t *testing.T) {
+	calldata, err := EncodeCall("deposit(uint256,address)", []types.ParsedParam{
+		{Type: "uint256", Value: "1000"},
+		{Type: "address", Value: "0xdead"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "6e553f65"+
+		"00000000000000000000000000000000000000000000000000000000000003e8"+
+		"000000000000000000000000000000000000000000000000000000000000dead", hex.EncodeToString(calldata))
+
+	_, err = EncodeCall("deposit(uint8)", []types.ParsedParam{{Type: "uint8", Value: "256"}})
+	assert.ErrorContains(t, err, "does not fit in uint8")
+}
+
+func TestEncodeArguments_RoundTrip(t *testing.T) {
+	params := []types.ParsedParam{
+		{Type: "string", Value: `"hi"`},
+		{Type: "int8", Value: "-5"},
+		{Type: "uint256[]", Elements: []types.ParsedParam{
+			{Type: "uint256", Value: "1"},
+			{Type: "uint256", Value: "2"},
+		}},
+		{Type: "bytes", Value: "0xdeadbeef"},
+		{Type: "bytes4", Value: "0x12345678"},
+		{Type: "bool", Value: "true"},
+		{Type: "(address,string)[2]", Elements: []types.ParsedParam{
+			{Type: "(address,string)", Elements: []types.ParsedParam{
+				{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+				{Type: "string", Value: `"a"`},
+			}},
+			{Type: "(address,string)", Elements: []types.ParsedParam{
+				{Type: "address", Value: "0x0000000000000000000000000000000000010000"},
+				{Type: "string", Value: `""`},
+			}},
+		}},
+		{Type: "uint8[2][]", Elements: []types.ParsedParam{
+			{Type: "uint8[2]", Elements: []types.ParsedParam{{Type: "uint8", Value: "3"}, {Type: "uint8", Value: "4"}}},
+		}},
+	}
+
+	argTypes := make([]string, len(params))
+	for i, param := range params {
+		argTypes[i] = param.Type
+	}
+
+	data, err := EncodeArguments(params)
+	require.NoError(t, err)
+	decoded, err := DecodeArguments(argTypes, data)
+	require.NoError(t, err)
+	assert.Equal(t, params, decoded)
+
+	// The first arguments encode as in TestDecodeArguments_Dynamic
+	data, err = EncodeArguments(params[:3])
+	require.NoError(t, err)
+	assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000060"+
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffb"+
+		"00000000000000000000000000000000000000000000000000000000000000a0", hex.EncodeToString(data[:96]))
+}
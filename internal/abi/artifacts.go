@@ -0,0 +1,208 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+// compilerVersionSuffix matches the compiler version in artifact file names, e.g. .0.8.19
+var compilerVersionSuffix = regexp.MustCompile(`(\.\d+){3}$`)
+
+// Artifact represents a contract compiled by Foundry
+type Artifact struct {
+	Name       string // Contract name, e.g. Vault
+	SourcePath string // Source file directory in the out folder, e.g. Vault.sol
+	ABI        *ABI
+	Bytecode   string // Creation bytecode as 0x-prefixed hex
+	Source     string // Source file from the compilation metadata, e.g. src/Vault.sol (empty when unknown)
+}
+
+// ArtifactSet holds all artifacts found in a Foundry out directory
+type ArtifactSet struct {
+	Artifacts []*Artifact
+}
+
+// foundryArtifact is the subset of a Foundry artifact JSON file that is used
+type foundryArtifact struct {
+	ABI      []Entry `json:"abi"`
+	Bytecode struct {
+		Object string `json:"object"`
+	} `json:"bytecode"`
+	Metadata struct {
+		Settings struct {
+			CompilationTarget map[string]string `json:"compilationTarget"`
+		} `json:"settings"`
+	} `json:"metadata"`
+}
+
+// source returns the source file the artifact was compiled from, if the metadata records it
+func (a foundryArtifact) source() string {
+	for source := range a.Metadata.Settings.CompilationTarget {
+		return source
+	}
+	return ""
+}
+
+// contractName returns the name of the compiled contract from the metadata, or else from
+// the artifact file name, without the compiler version Foundry appends when a project is
+// built with several (Vault.0.8.19.json)
+func (a foundryArtifact) contractName(fileName string) string {
+	for _, name := range a.Metadata.Settings.CompilationTarget {
+		return name
+	}
+	return compilerVersionSuffix.ReplaceAllString(strings.TrimSuffix(fileName, ".json"), "")
+}
+
+// LoadABIFile loads a JSON ABI file: a bare ABI array, or a single Foundry artifact
+func LoadABIFile(path string) (*ABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ABI file %s: %w", path, err)
+	}
+
+	var raw foundryArtifact
+	if err := json.Unmarshal(data, &raw); err == nil && raw.ABI != nil {
+		return FromEntries(raw.ABI), nil
+	}
+
+	contractABI, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI file %s: %w", path, err)
+	}
+	return contractABI, nil
+}
+
+// LoadArtifacts loads every contract artifact found under a Foundry out directory (out/**/*.json)
+func LoadArtifacts(dir string) (*ArtifactSet, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("artifacts path does not exist: %s", dir)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("artifacts path is not a directory: %s", dir)
+	}
+
+	set := &ArtifactSet{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// build-info holds compiler input/output, not contract artifacts
+		if d.IsDir() && d.Name() == "build-info" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact %s: %w", path, err)
+		}
+
+		var raw foundryArtifact
+		if err := json.Unmarshal(data, &raw); err != nil {
+			logger.Debug("skipped JSON file that is not a contract artifact", "file", path, "error", err.Error())
+			return nil
+		}
+		if raw.ABI == nil {
+			return nil // Not a contract artifact
+		}
+
+		set.Artifacts = append(set.Artifacts, &Artifact{
+			Name:       raw.contractName(d.Name()),
+			SourcePath: filepath.Base(filepath.Dir(path)),
+			ABI:        FromEntries(raw.ABI),
+			Bytecode:   raw.Bytecode.Object,
+			Source:     raw.source(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load artifacts: %w", err)
+	}
+
+	if len(set.Artifacts) == 0 {
+		return nil, fmt.Errorf("no contract artifacts found in %s", dir)
+	}
+
+	sort.Slice(set.Artifacts, func(i, j int) bool {
+		if set.Artifacts[i].Name != set.Artifacts[j].Name {
+			return set.Artifacts[i].Name < set.Artifacts[j].Name
+		}
+		return set.Artifacts[i].SourcePath < set.Artifacts[j].SourcePath
+	})
+
+	return set, nil
+}
+
+// Find returns the artifact for a contract name. Names shared by several sources can be
+// disambiguated with the source file, e.g. "Vault.sol:Vault". A source compiled with several
+// compiler versions has an artifact for each, which share the ABI; the first is returned.
+func (s *ArtifactSet) Find(name string) (*Artifact, error) {
+	source, contract, qualified := strings.Cut(name, ":")
+	if !qualified {
+		contract = name
+	}
+
+	var matches []*Artifact
+	for _, artifact := range s.Artifacts {
+		if artifact.Name == contract && (!qualified || artifact.SourcePath == filepath.Base(source)) {
+			matches = append(matches, artifact)
+		}
+	}
+
+	sources := make(map[string]bool)
+	for _, match := range matches {
+		sources[match.SourcePath] = true
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, fmt.Errorf("contract %s not found in artifacts", name)
+	case 1:
+		return matches[0], nil
+	default:
+		var candidates []string
+		for _, match := range matches {
+			if candidate := match.SourcePath + ":" + match.Name; !slices.Contains(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		return nil, fmt.Errorf("contract name %s is ambiguous, use one of: %s", name, strings.Join(candidates, ", "))
+	}
+}
+
+// MatchCreationCode returns the artifact whose creation bytecode starts the given
+// creation code, along with the ABI encoded constructor arguments that follow it.
+// The longest bytecode wins when several artifacts match.
+func (s *ArtifactSet) MatchCreationCode(code []byte) (*Artifact, []byte) {
+	var best *Artifact
+	var bestLength int
+	for _, artifact := range s.Artifacts {
+		// Bytecode with unlinked library placeholders is not valid hex and cannot match
+		bytecode, err := hex.DecodeString(strings.TrimPrefix(artifact.Bytecode, "0x"))
+		if err != nil || len(bytecode) == 0 || len(bytecode) <= bestLength {
+			continue
+		}
+		if bytes.HasPrefix(code, bytecode) {
+			best, bestLength = artifact, len(bytecode)
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best, code[bestLength:]
+}
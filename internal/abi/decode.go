@@ -0,0 +1,283 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// wordSize is the size of an ABI encoding slot
+const wordSize = 32
+
+// Selector returns the 4-byte function selector of a canonical signature
+func Selector(signature string) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(signature))
+	return hash.Sum(nil)[:4]
+}
+
+// MethodBySelector returns the method whose selector starts the calldata, if any
+func (a *ABI) MethodBySelector(calldata []byte) *Method {
+	if len(calldata) < 4 {
+		return nil
+	}
+	for i := range a.Methods {
+		if bytes.Equal(Selector(a.Methods[i].Signature()), calldata[:4]) {
+			return &a.Methods[i]
+		}
+	}
+	return nil
+}
+
+// DecodeCall decodes calldata of one of the ABI's methods into its method and arguments
+func (a *ABI) DecodeCall(calldata []byte) (*Method, []types.ParsedParam, error) {
+	method := a.MethodBySelector(calldata)
+	if method == nil {
+		if len(calldata) < 4 {
+			return nil, nil, fmt.Errorf("calldata is shorter than a selector")
+		}
+		return nil, nil, fmt.Errorf("no method with selector 0x%x", calldata[:4])
+	}
+
+	params, err := DecodeArguments(InputTypes(method.Inputs), calldata[4:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode arguments of %s: %w", method.Signature(), err)
+	}
+	return method, params, nil
+}
+
+// DecodeArguments decodes ABI encoded arguments of the given canonical types.
+// Values use the parser's conventions: decimal integers, checksummed addresses,
+// 0x-prefixed bytes and quoted strings.
+func DecodeArguments(argTypes []string, data []byte) ([]types.ParsedParam, error) {
+	params, err := decodeSequence(argTypes, data)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = []types.ParsedParam{}
+	}
+	return params, nil
+}
+
+// decodeSequence decodes the head/tail encoding of a tuple with the given component types
+func decodeSequence(componentTypes []string, data []byte) ([]types.ParsedParam, error) {
+	var result []types.ParsedParam
+	head := 0
+
+	for i, solType := range componentTypes {
+		var value types.ParsedParam
+		var err error
+
+		if isDynamicType(solType) {
+			offset, offsetErr := readLength(data, head)
+			if offsetErr != nil {
+				return nil, fmt.Errorf("component %d: %w", i, offsetErr)
+			}
+			value, err = decodeValue(solType, data[offset:])
+		} else {
+			if head > len(data) {
+				return nil, fmt.Errorf("component %d: data too short", i)
+			}
+			value, err = decodeValue(solType, data[head:])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("component %d: %w", i, err)
+		}
+
+		size, err := headSize(solType)
+		if err != nil {
+			return nil, err
+		}
+		head += size
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// decodeValue decodes a single value whose encoding starts at data
+func decodeValue(solType string, data []byte) (types.ParsedParam, error) {
+	switch {
+	case IsArrayType(solType):
+		elemType, length, err := SplitArrayType(solType)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+
+		var count int
+		elements := data
+		if length == "" {
+			if count, err = readLength(data, 0); err != nil {
+				return types.ParsedParam{}, err
+			}
+			elements = data[wordSize:]
+		} else if count, err = strconv.Atoi(length); err != nil {
+			return types.ParsedParam{}, fmt.Errorf("invalid array length in %s", solType)
+		}
+
+		// Every element takes at least one slot, which bounds the count by the data size
+		if count > len(elements)/wordSize {
+			return types.ParsedParam{}, fmt.Errorf("array %s of %d elements exceeds the data", solType, count)
+		}
+
+		componentTypes := make([]string, count)
+		for i := range componentTypes {
+			componentTypes[i] = elemType
+		}
+		values, err := decodeSequence(componentTypes, elements)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		return types.ParsedParam{Type: solType, Elements: values}, nil
+
+	case IsTupleType(solType):
+		componentTypes, err := SplitTupleType(solType)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		components, err := decodeSequence(componentTypes, data)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		return types.ParsedParam{Type: solType, Elements: components}, nil
+
+	case solType == "string", solType == "bytes":
+		length, err := readLength(data, 0)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		if wordSize+length > len(data) {
+			return types.ParsedParam{}, fmt.Errorf("%s of %d bytes exceeds the data", solType, length)
+		}
+		content := data[wordSize : wordSize+length]
+		if solType == "string" {
+			return types.ParsedParam{Type: solType, Value: fmt.Sprintf(`"%s"`, content)}, nil
+		}
+		return types.ParsedParam{Type: solType, Value: "0x" + hex.EncodeToString(content)}, nil
+	}
+
+	word, err := readWord(data, 0)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	switch {
+	case strings.HasPrefix(solType, "uint"):
+		return types.ParsedParam{Type: solType, Value: new(big.Int).SetBytes(word).String()}, nil
+	case strings.HasPrefix(solType, "int"):
+		n := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return types.ParsedParam{Type: solType, Value: n.String()}, nil
+	case solType == "address":
+		address, err := utils.ChecksumAddress(hex.EncodeToString(word[12:]))
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		return types.ParsedParam{Type: solType, Value: address}, nil
+	case solType == "bool":
+		n := new(big.Int).SetBytes(word)
+		if n.Cmp(big.NewInt(1)) > 0 {
+			return types.ParsedParam{}, fmt.Errorf("invalid bool encoding 0x%x", word)
+		}
+		return types.ParsedParam{Type: solType, Value: strconv.FormatBool(n.Sign() == 1)}, nil
+	case strings.HasPrefix(solType, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(solType, "bytes"))
+		if err != nil || size < 1 || size > wordSize {
+			return types.ParsedParam{}, fmt.Errorf("invalid fixed bytes type: %s", solType)
+		}
+		return types.ParsedParam{Type: solType, Value: "0x" + hex.EncodeToString(word[:size])}, nil
+	default:
+		return types.ParsedParam{}, fmt.Errorf("unsupported ABI type: %s", solType)
+	}
+}
+
+// isDynamicType reports whether a type is encoded in the tail of its enclosing tuple
+func isDynamicType(solType string) bool {
+	switch {
+	case solType == "string", solType == "bytes":
+		return true
+	case IsArrayType(solType):
+		elemType, length, err := SplitArrayType(solType)
+		return err != nil || length == "" || isDynamicType(elemType)
+	case IsTupleType(solType):
+		componentTypes, err := SplitTupleType(solType)
+		if err != nil {
+			return true
+		}
+		for _, componentType := range componentTypes {
+			if isDynamicType(componentType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize returns the number of bytes a type takes in the head of its enclosing tuple
+func headSize(solType string) (int, error) {
+	if isDynamicType(solType) {
+		return wordSize, nil
+	}
+
+	switch {
+	case IsArrayType(solType):
+		elemType, length, err := SplitArrayType(solType)
+		if err != nil {
+			return 0, err
+		}
+		count, err := strconv.Atoi(length)
+		if err != nil {
+			return 0, fmt.Errorf("invalid array length in %s", solType)
+		}
+		elemSize, err := headSize(elemType)
+		return count * elemSize, err
+	case IsTupleType(solType):
+		componentTypes, err := SplitTupleType(solType)
+		if err != nil {
+			return 0, err
+		}
+		total := 0
+		for _, componentType := range componentTypes {
+			size, err := headSize(componentType)
+			if err != nil {
+				return 0, err
+			}
+			total += size
+		}
+		return total, nil
+	default:
+		return wordSize, nil
+	}
+}
+
+// readWord returns the 32-byte slot at offset
+func readWord(data []byte, offset int) ([]byte, error) {
+	if offset < 0 || offset+wordSize > len(data) {
+		return nil, fmt.Errorf("data too short: need %d bytes, have %d", offset+wordSize, len(data))
+	}
+	return data[offset : offset+wordSize], nil
+}
+
+// readLength reads the slot at offset as a length or offset that must lie within the data
+func readLength(data []byte, offset int) (int, error) {
+	word, err := readWord(data, offset)
+	if err != nil {
+		return 0, err
+	}
+	n := new(big.Int).SetBytes(word)
+	if !n.IsInt64() || n.Int64() > int64(len(data)) {
+		return 0, fmt.Errorf("length or offset %s exceeds the data", n)
+	}
+	return int(n.Int64()), nil
+}
@@ -0,0 +1,203 @@
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// EncodeCall encodes the calldata of a call to the method with the given canonical
+// signature, e.g. "deposit(uint256,address)"
+func EncodeCall(signature string, params []types.ParsedParam) ([]byte, error) {
+	data, err := EncodeArguments(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments of %s: %w", signature, err)
+	}
+	return append(Selector(signature), data...), nil
+}
+
+// EncodeArguments ABI encodes arguments whose values use the parser's conventions,
+// the inverse of DecodeArguments. Bytes values may also be raw strings, as in Echidna
+// reproducers.
+func EncodeArguments(params []types.ParsedParam) ([]byte, error) {
+	return encodeSequence(params)
+}
+
+// encodeSequence encodes values in the head/tail encoding of a tuple
+func encodeSequence(params []types.ParsedParam) ([]byte, error) {
+	var head, tail []byte
+	headLength := 0
+	for _, param := range params {
+		size, err := headSize(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		headLength += size
+	}
+
+	for i, param := range params {
+		encoded, err := encodeValue(param)
+		if err != nil {
+			return nil, fmt.Errorf("component %d: %w", i, err)
+		}
+
+		if isDynamicType(param.Type) {
+			head = append(head, encodeWord(big.NewInt(int64(headLength+len(tail))))...)
+			tail = append(tail, encoded...)
+		} else {
+			head = append(head, encoded...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+// encodeValue encodes a single value
+func encodeValue(param types.ParsedParam) ([]byte, error) {
+	switch {
+	case IsArrayType(param.Type):
+		elemType, length, err := SplitArrayType(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		for i, element := range param.Elements {
+			if element.Type != elemType {
+				return nil, fmt.Errorf("element %d of %s has type %s", i, param.Type, element.Type)
+			}
+		}
+
+		elements, err := encodeSequence(param.Elements)
+		if err != nil {
+			return nil, err
+		}
+		if length == "" {
+			return append(encodeWord(big.NewInt(int64(len(param.Elements)))), elements...), nil
+		}
+		if length != strconv.Itoa(len(param.Elements)) {
+			return nil, fmt.Errorf("array %s has %d elements", param.Type, len(param.Elements))
+		}
+		return elements, nil
+
+	case IsTupleType(param.Type):
+		componentTypes, err := SplitTupleType(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(componentTypes) != len(param.Elements) {
+			return nil, fmt.Errorf("tuple %s has %d components", param.Type, len(param.Elements))
+		}
+		return encodeSequence(param.Elements)
+
+	case param.Type == "string", param.Type == "bytes":
+		content := []byte(unquoteString(param.Value))
+		if param.Type == "bytes" {
+			var err error
+			if content, err = bytesValue(param.Value); err != nil {
+				return nil, err
+			}
+		}
+		padded := make([]byte, (len(content)+wordSize-1)/wordSize*wordSize)
+		copy(padded, content)
+		return append(encodeWord(big.NewInt(int64(len(content)))), padded...), nil
+
+	case strings.HasPrefix(param.Type, "uint"), strings.HasPrefix(param.Type, "int"):
+		return encodeInteger(param)
+
+	case param.Type == "address":
+		normalized, err := utils.NormalizeAddress(param.Value)
+		if err != nil {
+			return nil, err
+		}
+		address, _ := hex.DecodeString(normalized[2:])
+		return append(make([]byte, wordSize-len(address)), address...), nil
+
+	case param.Type == "bool":
+		value, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool value: %q", param.Value)
+		}
+		if value {
+			return encodeWord(big.NewInt(1)), nil
+		}
+		return encodeWord(new(big.Int)), nil
+
+	case strings.HasPrefix(param.Type, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(param.Type, "bytes"))
+		if err != nil || size < 1 || size > wordSize {
+			return nil, fmt.Errorf("invalid fixed bytes type: %s", param.Type)
+		}
+		content, err := bytesValue(param.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > size {
+			return nil, fmt.Errorf("value %s does not fit in %s", param.Value, param.Type)
+		}
+		// Fixed bytes are left-aligned
+		word := make([]byte, wordSize)
+		copy(word, content)
+		return word, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported ABI type: %s", param.Type)
+	}
+}
+
+// encodeInteger encodes a decimal integer as a two's complement word, checking its range
+func encodeInteger(param types.ParsedParam) ([]byte, error) {
+	signed := strings.HasPrefix(param.Type, "int")
+	bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(param.Type, "u"), "int"))
+	if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+		return nil, fmt.Errorf("invalid integer type: %s", param.Type)
+	}
+
+	n, ok := new(big.Int).SetString(param.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer value: %q", param.Value)
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	lowest := new(big.Int)
+	if signed {
+		limit.Rsh(limit, 1)
+		lowest.Neg(limit)
+	}
+	if n.Cmp(lowest) < 0 || n.Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("value %s does not fit in %s", param.Value, param.Type)
+	}
+
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return encodeWord(n), nil
+}
+
+// encodeWord encodes a non-negative integer as a 32-byte slot
+func encodeWord(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, wordSize))
+}
+
+// bytesValue returns the content of a bytes value, which may be 0x-prefixed hex or raw bytes
+func bytesValue(value string) ([]byte, error) {
+	digits, ok := strings.CutPrefix(value, "0x")
+	if !ok {
+		return []byte(value), nil
+	}
+	content, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex bytes value: %q", value)
+	}
+	return content, nil
+}
+
+// unquoteString strips the Solidity quotes the parser adds around string values
+func unquoteString(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package abi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSignature splits a method signature such as "deposit(uint256,address)" into name and argument types
+func ParseSignature(signature string) (string, []string, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return "", nil, fmt.Errorf("invalid method signature: %q", signature)
+	}
+
+	name := signature[:open]
+	args := signature[open+1 : len(signature)-1]
+	if args == "" {
+		return name, nil, nil
+	}
+
+	argTypes, err := SplitTypeList(args)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid method signature %q: %w", signature, err)
+	}
+	return name, argTypes, nil
+}
+
+// SplitTypeList splits a comma separated type list, ignoring commas nested inside parentheses
+func SplitTypeList(list string) ([]string, error) {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	return append(parts, strings.TrimSpace(list[start:])), nil
+}
+
+// IsArrayType reports whether a Solidity type is a fixed or dynamic array
+func IsArrayType(solType string) bool {
+	return strings.HasSuffix(solType, "]")
+}
+
+// IsTupleType reports whether a Solidity type is a tuple such as "(uint256,address)"
+func IsTupleType(solType string) bool {
+	return strings.HasPrefix(solType, "(") && strings.HasSuffix(solType, ")")
+}
+
+// SplitArrayType splits an array type into its element type and length ("" for dynamic arrays)
+func SplitArrayType(solType string) (string, string, error) {
+	open := strings.LastIndex(solType, "[")
+	if open <= 0 || !IsArrayType(solType) {
+		return "", "", fmt.Errorf("invalid array type: %q", solType)
+	}
+	return solType[:open], solType[open+1 : len(solType)-1], nil
+}
+
+// SplitTupleType returns the component types of a tuple type
+func SplitTupleType(solType string) ([]string, error) {
+	if !IsTupleType(solType) {
+		return nil, fmt.Errorf("invalid tuple type: %q", solType)
+	}
+	inner := solType[1 : len(solType)-1]
+	if inner == "" {
+		return nil, nil
+	}
+	return SplitTypeList(inner)
+}
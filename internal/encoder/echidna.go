@@ -0,0 +1,312 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// EncodeEchidna converts parsed calls back to an Echidna reproducer
+func EncodeEchidna(calls []types.ParsedCall) (types.EchidnaReproducer, error) {
+	reproducer := types.EchidnaReproducer{}
+
+	for i, call := range calls {
+		tx, err := encodeTransaction(call)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode call %d: %w", i, err)
+		}
+		reproducer = append(reproducer, tx)
+	}
+
+	return reproducer, nil
+}
+
+// MarshalEchidna converts parsed calls to a reproducer JSON document laid out as Echidna
+// writes it: compact, with keys in alphabetical order and no trailing newline
+func MarshalEchidna(calls []types.ParsedCall) ([]byte, error) {
+	reproducer, err := EncodeEchidna(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Echidna does not escape HTML characters in strings
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(reproducer); err != nil {
+		return nil, fmt.Errorf("failed to marshal reproducer: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// WriteEchidnaFile writes parsed calls to a file in Echidna's reproducer JSON format
+func WriteEchidnaFile(path string, calls []types.ParsedCall) error {
+	data, err := MarshalEchidna(calls)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write reproducer %s: %w", path, err)
+	}
+	return nil
+}
+
+// encodeTransaction converts a parsed call to an Echidna transaction
+func encodeTransaction(call types.ParsedCall) (types.Transaction, error) {
+	timeDelay, err := encodeDelay(call.HasDelay, call.DelayValue)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("invalid time delay: %w", err)
+	}
+
+	blockDelay, err := encodeDelay(call.HasBlockDelay, call.BlockDelayValue)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("invalid block delay: %w", err)
+	}
+
+	value, err := encodeQuantity(call.Value)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("invalid value: %w", err)
+	}
+
+	gasPrice, err := encodeQuantity(call.GasPrice)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("invalid gas price: %w", err)
+	}
+
+	tx := types.Transaction{
+		Call:     types.Call{Tag: "NoCall"},
+		Delay:    []string{timeDelay, blockDelay},
+		Dst:      call.Dst,
+		Gas:      call.Gas,
+		GasPrice: gasPrice,
+		Src:      call.Src,
+		Value:    value,
+	}
+
+	switch call.Kind {
+	case types.CallKindRaw:
+		tx.Call = types.Call{Tag: "SolCalldata", Contents: call.Calldata}
+		return tx, nil
+	case types.CallKindCreate:
+		tx.Call = types.Call{Tag: "SolCreate", Contents: call.Calldata}
+		return tx, nil
+	case types.CallKindUnsupported:
+		return types.Transaction{}, fmt.Errorf("cannot encode skipped transaction: %s", call.Note)
+	}
+
+	// Calls without a function name are pure delays
+	if call.FunctionName == "" {
+		return tx, nil
+	}
+
+	params := make([]interface{}, 0, len(call.Parameters))
+	for i, param := range call.Parameters {
+		encoded, err := encodeParameter(param)
+		if err != nil {
+			return types.Transaction{}, fmt.Errorf("parameter %d of %s: %w", i, call.FunctionName, err)
+		}
+		params = append(params, encoded)
+	}
+
+	tx.Call = types.Call{
+		Tag:      "SolCall",
+		Contents: []interface{}{call.FunctionName, params},
+	}
+	return tx, nil
+}
+
+// encodeDelay encodes a decimal delay as a 32-byte hex word
+func encodeDelay(hasDelay bool, value string) (string, error) {
+	delay := new(big.Int)
+	if hasDelay {
+		if _, ok := delay.SetString(value, 10); !ok {
+			return "", fmt.Errorf("not a decimal number: %q", value)
+		}
+	}
+	return fmt.Sprintf("0x%064x", delay), nil
+}
+
+// encodeQuantity encodes a hex quantity as a 32-byte hex word
+func encodeQuantity(value string) (string, error) {
+	n, err := utils.ParseHexQuantity(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%064x", n), nil
+}
+
+// taggedValue is Echidna's JSON encoding of ABI values and types
+type taggedValue struct {
+	Contents interface{} `json:"contents,omitempty"`
+	Tag      string      `json:"tag"`
+}
+
+// encodeParameter converts a parsed parameter to Echidna's tagged ABI value encoding.
+// Single-field constructors such as AbiAddress carry their value bare.
+func encodeParameter(param types.ParsedParam) (taggedValue, error) {
+	switch {
+	case abi.IsArrayType(param.Type):
+		return encodeArray(param)
+	case abi.IsTupleType(param.Type):
+		components, err := encodeParameterList(param.Elements)
+		if err != nil {
+			return taggedValue{}, err
+		}
+		return taggedValue{Tag: "AbiTuple", Contents: components}, nil
+	case strings.HasPrefix(param.Type, "uint"):
+		size, err := typeSize(param.Type, "uint")
+		if err != nil {
+			return taggedValue{}, err
+		}
+		return taggedValue{Tag: "AbiUInt", Contents: []interface{}{size, param.Value}}, nil
+	case strings.HasPrefix(param.Type, "int"):
+		size, err := typeSize(param.Type, "int")
+		if err != nil {
+			return taggedValue{}, err
+		}
+		return taggedValue{Tag: "AbiInt", Contents: []interface{}{size, param.Value}}, nil
+	case param.Type == "address":
+		return taggedValue{Tag: "AbiAddress", Contents: param.Value}, nil
+	case param.Type == "bool":
+		value, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return taggedValue{}, fmt.Errorf("invalid bool value: %q", param.Value)
+		}
+		return taggedValue{Tag: "AbiBool", Contents: value}, nil
+	case param.Type == "string":
+		return taggedValue{Tag: "AbiString", Contents: unquote(param.Value)}, nil
+	case param.Type == "bytes":
+		return taggedValue{Tag: "AbiBytesDynamic", Contents: param.Value}, nil
+	case strings.HasPrefix(param.Type, "bytes"):
+		size, err := typeSize(param.Type, "bytes")
+		if err != nil {
+			return taggedValue{}, err
+		}
+		return taggedValue{Tag: "AbiBytes", Contents: []interface{}{size, param.Value}}, nil
+	default:
+		return taggedValue{}, fmt.Errorf("unsupported type: %s", param.Type)
+	}
+}
+
+// encodeArray encodes fixed arrays as [length, type, values] and dynamic arrays as [type, values]
+func encodeArray(param types.ParsedParam) (taggedValue, error) {
+	elemType, length, err := abi.SplitArrayType(param.Type)
+	if err != nil {
+		return taggedValue{}, err
+	}
+
+	typeDescriptor, err := encodeType(elemType)
+	if err != nil {
+		return taggedValue{}, err
+	}
+
+	elements, err := encodeParameterList(param.Elements)
+	if err != nil {
+		return taggedValue{}, err
+	}
+
+	if length == "" {
+		return taggedValue{Tag: "AbiArrayDynamic", Contents: []interface{}{typeDescriptor, elements}}, nil
+	}
+
+	size, err := strconv.Atoi(length)
+	if err != nil {
+		return taggedValue{}, fmt.Errorf("invalid array length in %s", param.Type)
+	}
+	return taggedValue{Tag: "AbiArray", Contents: []interface{}{size, typeDescriptor, elements}}, nil
+}
+
+// encodeParameterList encodes a list of parameters
+func encodeParameterList(params []types.ParsedParam) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(params))
+	for i, param := range params {
+		encoded, err := encodeParameter(param)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		result = append(result, encoded)
+	}
+	return result, nil
+}
+
+// encodeType converts a Solidity type to Echidna's ABI type descriptor
+func encodeType(solType string) (taggedValue, error) {
+	switch {
+	case abi.IsArrayType(solType):
+		elemType, length, err := abi.SplitArrayType(solType)
+		if err != nil {
+			return taggedValue{}, err
+		}
+		elemDescriptor, err := encodeType(elemType)
+		if err != nil {
+			return taggedValue{}, err
+		}
+		if length == "" {
+			return taggedValue{Tag: "AbiArrayDynamicType", Contents: elemDescriptor}, nil
+		}
+		size, err := strconv.Atoi(length)
+		if err != nil {
+			return taggedValue{}, fmt.Errorf("invalid array length in %s", solType)
+		}
+		return taggedValue{Tag: "AbiArrayType", Contents: []interface{}{size, elemDescriptor}}, nil
+	case abi.IsTupleType(solType):
+		componentTypes, err := abi.SplitTupleType(solType)
+		if err != nil {
+			return taggedValue{}, err
+		}
+		components := make([]interface{}, 0, len(componentTypes))
+		for _, componentType := range componentTypes {
+			descriptor, err := encodeType(componentType)
+			if err != nil {
+				return taggedValue{}, err
+			}
+			components = append(components, descriptor)
+		}
+		return taggedValue{Tag: "AbiTupleType", Contents: components}, nil
+	case strings.HasPrefix(solType, "uint"):
+		size, err := typeSize(solType, "uint")
+		return taggedValue{Tag: "AbiUIntType", Contents: size}, err
+	case strings.HasPrefix(solType, "int"):
+		size, err := typeSize(solType, "int")
+		return taggedValue{Tag: "AbiIntType", Contents: size}, err
+	case solType == "address":
+		return taggedValue{Tag: "AbiAddressType"}, nil
+	case solType == "bool":
+		return taggedValue{Tag: "AbiBoolType"}, nil
+	case solType == "string":
+		return taggedValue{Tag: "AbiStringType"}, nil
+	case solType == "bytes":
+		return taggedValue{Tag: "AbiBytesDynamicType"}, nil
+	case strings.HasPrefix(solType, "bytes"):
+		size, err := typeSize(solType, "bytes")
+		return taggedValue{Tag: "AbiBytesType", Contents: size}, err
+	default:
+		return taggedValue{}, fmt.Errorf("unsupported type: %s", solType)
+	}
+}
+
+// typeSize extracts the bit or byte size from a sized type such as uint8 or bytes32
+func typeSize(solType, prefix string) (int, error) {
+	size, err := strconv.Atoi(strings.TrimPrefix(solType, prefix))
+	if err != nil {
+		return 0, fmt.Errorf("invalid sized type: %s", solType)
+	}
+	return size, nil
+}
+
+// unquote strips the Solidity quotes the parser adds around string values
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// zeroWord is a zero quantity as Echidna writes it, padded to 32 bytes
+const zeroWord = "0x0000000000000000000000000000000000000000000000000000000000000000"
+
+func TestWriteEchidnaFile_RoundTrip(t *testing.T) {
+	calls := []types.ParsedCall{
+		{
+			FunctionName: "deposit",
+			Parameters: []types.ParsedParam{
+				{Type: "uint256", Value: "3625"},
+				{Type: "int8", Value: "-3"},
+				{Type: "address", Value: "0x1234567890123456789012345678901234567890"},
+				{Type: "bool", Value: "true"},
+				{Type: "string", Value: `"hello"`},
+				{Type: "bytes", Value: "0xdeadbeef"},
+				{Type: "bytes4", Value: "0x12345678"},
+				{Type: "uint8[]", Elements: []types.ParsedParam{{Type: "uint8", Value: "1"}}},
+				{Type: "(address,uint256)[2]", Elements: []types.ParsedParam{
+					{Type: "(address,uint256)", Elements: []types.ParsedParam{
+						{Type: "address", Value: "0x0000000000000000000000000000000000010000"},
+						{Type: "uint256", Value: "1"},
+					}},
+					{Type: "(address,uint256)", Elements: []types.ParsedParam{
+						{Type: "address", Value: "0x0000000000000000000000000000000000020000"},
+						{Type: "uint256", Value: "2"},
+					}},
+				}},
+			},
+			Src:             "0x0000000000000000000000000000000000010000",
+			Dst:             "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496",
+			Value:           zeroWord,
+			GasPrice:        zeroWord,
+			Gas:             1000000,
+			HasDelay:        true,
+			DelayValue:      "4294967296",
+			HasBlockDelay:   true,
+			BlockDelayValue: "12",
+		},
+		{
+			Parameters:      []types.ParsedParam{},
+			Src:             "0x0000000000000000000000000000000000020000",
+			Value:           zeroWord,
+			GasPrice:        zeroWord,
+			HasBlockDelay:   true,
+			BlockDelayValue: "3",
+		},
+		{
+			Kind:       types.CallKindRaw,
+			Parameters: []types.ParsedParam{},
+			Src:        "0x0000000000000000000000000000000000020000",
+			Dst:        "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496",
+			Value:      zeroWord,
+			GasPrice:   zeroWord,
+			Calldata:   "0xcafebabe01",
+		},
+		{
+			Kind:       types.CallKindCreate,
+			Parameters: []types.ParsedParam{},
+			Src:        "0x0000000000000000000000000000000000020000",
+			Value:      zeroWord,
+			GasPrice:   zeroWord,
+			Calldata:   "0x6080604052",
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "reproducer.txt")
+	require.NoError(t, WriteEchidnaFile(path, calls))
+
+	parsed, err := parser.ParseReproducerFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, calls, parsed)
+}
+
+func TestEncodeEchidna_SkippedTransaction(t *testing.T) {
+	_, err := EncodeEchidna([]types.ParsedCall{{Kind: types.CallKindUnsupported, Note: "SolCreate transactions are not supported"}})
+	assert.ErrorContains(t, err, "SolCreate")
+}
+
+func TestMarshalEchidna_Golden(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "echidna_reproducer.txt"))
+	require.NoError(t, err)
+
+	calls, err := parser.ParseData(golden)
+	require.NoError(t, err)
+
+	data, err := MarshalEchidna(calls)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(data))
+
+	// Quantities are padded to 32 bytes whatever their source
+	calls[0].Value, calls[0].GasPrice = "0xde0b6b3a7640000", ""
+	data, err = MarshalEchidna(calls)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(data))
+}
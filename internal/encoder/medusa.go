@@ -0,0 +1,278 @@
+package encoder
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// medusaGasLimit is Medusa's default transaction gas limit, used for calls that do not record one
+const medusaGasLimit = 12500000
+
+// DefaultMedusaTarget is the address Medusa deploys the first target contract at
+const DefaultMedusaTarget = "0xA647ff3c36cFab592509E13860ab8c4F28781a66"
+
+// MedusaOptions controls how calls are written as a Medusa call sequence
+type MedusaOptions struct {
+	ABI    *abi.ABI // Target contract ABI providing the field names of tuple arguments (optional)
+	Target string   // Address every call is sent to, the calls' own targets when empty
+}
+
+// errUnnamedTuple reports a tuple value whose field names, which Medusa keys tuples by, are unknown
+var errUnnamedTuple = errors.New("tuple field names are unknown")
+
+// EncodeMedusa converts parsed calls to a Medusa call sequence. Every call carries its ABI
+// encoded data; function calls also carry their decoded values, except calls with tuple
+// arguments when options.ABI does not provide the field names. Medusa sequences have no
+// pure delays, so their delays are added to those of the next call and trailing ones are
+// dropped. Deployments and skipped transactions cannot be encoded.
+func EncodeMedusa(calls []types.ParsedCall, options MedusaOptions) (types.MedusaCallSequence, error) {
+	sequence := types.MedusaCallSequence{}
+	nonces := make(map[string]uint64)
+	var timeDelay, blockDelay uint64
+
+	for i, call := range calls {
+		callTime, err := medusaDelay(call.HasDelay, call.DelayValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time delay of call %d: %w", i, err)
+		}
+		callBlocks, err := medusaDelay(call.HasBlockDelay, call.BlockDelayValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block delay of call %d: %w", i, err)
+		}
+		timeDelay += callTime
+		blockDelay += callBlocks
+
+		if call.Kind == types.CallKindFunction && call.FunctionName == "" {
+			continue
+		}
+
+		if options.Target != "" {
+			call.Dst = options.Target
+		}
+		medusaCall, err := encodeMedusaCall(call, options.ABI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode call %d: %w", i, err)
+		}
+		// Medusa fills in the nonces again when it replays the sequence
+		medusaCall.Nonce = nonces[strings.ToLower(call.Src)]
+		nonces[strings.ToLower(call.Src)]++
+
+		sequence = append(sequence, types.MedusaCallSequenceElement{
+			Call:                medusaCall,
+			BlockNumberDelay:    blockDelay,
+			BlockTimestampDelay: timeDelay,
+		})
+		timeDelay, blockDelay = 0, 0
+	}
+
+	if timeDelay > 0 || blockDelay > 0 {
+		logger.Debug("dropped trailing delay, Medusa sequences end with a call", "time", timeDelay, "blocks", blockDelay)
+	}
+	return sequence, nil
+}
+
+// MarshalMedusa converts parsed calls to an indented Medusa call sequence JSON document
+func MarshalMedusa(calls []types.ParsedCall, options MedusaOptions) ([]byte, error) {
+	sequence, err := EncodeMedusa(calls, options)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(sequence, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal call sequence: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteMedusaFile writes parsed calls to a file in Medusa's call sequence JSON format
+func WriteMedusaFile(path string, calls []types.ParsedCall, options MedusaOptions) error {
+	data, err := MarshalMedusa(calls, options)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write call sequence %s: %w", path, err)
+	}
+	return nil
+}
+
+// encodeMedusaCall converts a function or raw call to the message Medusa sends
+func encodeMedusaCall(call types.ParsedCall, contractABI *abi.ABI) (types.MedusaCall, error) {
+	gas := call.Gas
+	if gas == 0 {
+		gas = medusaGasLimit
+	}
+	value, err := medusaQuantity(call.Value)
+	if err != nil {
+		return types.MedusaCall{}, fmt.Errorf("invalid value: %w", err)
+	}
+	gasPrice, err := medusaQuantity(call.GasPrice)
+	if err != nil {
+		return types.MedusaCall{}, fmt.Errorf("invalid gas price: %w", err)
+	}
+	message := types.MedusaCall{
+		From:     call.Src,
+		To:       call.Dst,
+		Value:    value,
+		GasLimit: gas,
+		GasPrice: gasPrice,
+	}
+	if message.To == "" {
+		return types.MedusaCall{}, fmt.Errorf("call has no target address")
+	}
+
+	switch call.Kind {
+	case types.CallKindRaw:
+		message.Data = call.Calldata
+		return message, nil
+	case types.CallKindCreate:
+		return types.MedusaCall{}, fmt.Errorf("Medusa call sequences cannot deploy contracts")
+	case types.CallKindUnsupported:
+		return types.MedusaCall{}, fmt.Errorf("cannot encode skipped transaction: %s", call.Note)
+	}
+
+	argTypes := make([]string, len(call.Parameters))
+	for i, param := range call.Parameters {
+		argTypes[i] = param.Type
+	}
+	signature := call.FunctionName + "(" + strings.Join(argTypes, ",") + ")"
+
+	calldata, err := abi.EncodeCall(signature, call.Parameters)
+	if err != nil {
+		return types.MedusaCall{}, err
+	}
+	message.Data = "0x" + hex.EncodeToString(calldata)
+
+	var args []abi.Argument
+	if contractABI != nil {
+		if method, _, err := contractABI.Resolve(call.FunctionName, argTypes); err == nil {
+			args = method.Inputs
+		}
+	}
+
+	inputValues := make([]interface{}, 0, len(call.Parameters))
+	for i, param := range call.Parameters {
+		var arg *abi.Argument
+		if args != nil {
+			arg = &args[i]
+		}
+
+		value, err := medusaValue(param, arg)
+		if errors.Is(err, errUnnamedTuple) {
+			logger.Debug("call kept as calldata only, Medusa keys tuple values by field name", "function", signature)
+			return message, nil
+		}
+		if err != nil {
+			return types.MedusaCall{}, fmt.Errorf("parameter %d of %s: %w", i, call.FunctionName, err)
+		}
+		inputValues = append(inputValues, value)
+	}
+
+	message.DataAbiValues = &types.MedusaDataAbiValues{
+		MethodSignature: signature,
+		InputValues:     inputValues,
+	}
+	return message, nil
+}
+
+// medusaValue converts a parsed parameter to Medusa's JSON encoding of ABI values: decimal
+// integer strings, hex bytes, arrays as JSON arrays and tuples as objects keyed by field name
+func medusaValue(param types.ParsedParam, arg *abi.Argument) (interface{}, error) {
+	switch {
+	case abi.IsArrayType(param.Type):
+		var elementArg *abi.Argument
+		if arg != nil {
+			element := arg.Element()
+			elementArg = &element
+		}
+		elements := make([]interface{}, 0, len(param.Elements))
+		for i, element := range param.Elements {
+			value, err := medusaValue(element, elementArg)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			elements = append(elements, value)
+		}
+		return elements, nil
+	case abi.IsTupleType(param.Type):
+		if arg == nil || len(arg.Components) != len(param.Elements) {
+			return nil, errUnnamedTuple
+		}
+		fields := make(map[string]interface{}, len(param.Elements))
+		for i, component := range param.Elements {
+			if arg.Components[i].Name == "" {
+				return nil, errUnnamedTuple
+			}
+			value, err := medusaValue(component, &arg.Components[i])
+			if err != nil {
+				return nil, fmt.Errorf("component %d: %w", i, err)
+			}
+			fields[arg.Components[i].Name] = value
+		}
+		return fields, nil
+	case param.Type == "bool":
+		value, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool value: %q", param.Value)
+		}
+		return value, nil
+	case param.Type == "string":
+		return unquote(param.Value), nil
+	case strings.HasPrefix(param.Type, "bytes"):
+		return medusaBytes(param)
+	default:
+		return param.Value, nil
+	}
+}
+
+// medusaBytes returns a bytes value as 0x-prefixed hex, padding fixed bytes to their size
+func medusaBytes(param types.ParsedParam) (string, error) {
+	digits, ok := strings.CutPrefix(param.Value, "0x")
+	if ok {
+		if _, err := hex.DecodeString(digits); err != nil {
+			return "", fmt.Errorf("invalid hex bytes value: %q", param.Value)
+		}
+	} else {
+		digits = hex.EncodeToString([]byte(param.Value))
+	}
+
+	if size, err := strconv.Atoi(strings.TrimPrefix(param.Type, "bytes")); err == nil && len(digits) < size*2 {
+		digits += strings.Repeat("0", size*2-len(digits))
+	}
+	return "0x" + strings.ToLower(digits), nil
+}
+
+// medusaQuantity converts a hex quantity, which Echidna pads to 32 bytes, to the minimal
+// hex form Medusa accepts
+func medusaQuantity(value string) (string, error) {
+	n, err := utils.ParseHexQuantity(value)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + n.Text(16), nil
+}
+
+// medusaDelay converts a decimal delay to the integer Medusa records
+func medusaDelay(hasDelay bool, value string) (uint64, error) {
+	if !hasDelay {
+		return 0, nil
+	}
+	delay, ok := new(big.Int).SetString(value, 10)
+	if !ok || delay.Sign() < 0 || !delay.IsUint64() {
+		return 0, fmt.Errorf("not a 64-bit decimal number: %q", value)
+	}
+	return delay.Uint64(), nil
+}
@@ -0,0 +1,137 @@
+package encoder
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+func TestWriteMedusaFile_RoundTrip(t *testing.T) {
+	calls := []types.ParsedCall{
+		{
+			FunctionName: "deposit",
+			Parameters: []types.ParsedParam{
+				{Type: "uint256", Value: "3625"},
+				{Type: "int8", Value: "-3"},
+				{Type: "address", Value: "0x1234567890123456789012345678901234567890"},
+				{Type: "bool", Value: "true"},
+				{Type: "string", Value: `"hello"`},
+				{Type: "bytes", Value: "0xdeadbeef"},
+				{Type: "bytes4", Value: "0x12345678"},
+				{Type: "uint8[]", Elements: []types.ParsedParam{{Type: "uint8", Value: "1"}}},
+				{Type: "address[2]", Elements: []types.ParsedParam{
+					{Type: "address", Value: "0x0000000000000000000000000000000000010000"},
+					{Type: "address", Value: "0x0000000000000000000000000000000000020000"},
+				}},
+			},
+			Src:             "0x0000000000000000000000000000000000010000",
+			Dst:             "0xa647ff3c36cfab592509e13860ab8c4f28781a66",
+			Value:           "0x0",
+			GasPrice:        "0x1",
+			Gas:             12500000,
+			HasDelay:        true,
+			DelayValue:      "30",
+			HasBlockDelay:   true,
+			BlockDelayValue: "12",
+		},
+		{
+			Kind:       types.CallKindRaw,
+			Parameters: []types.ParsedParam{},
+			Src:        "0x0000000000000000000000000000000000020000",
+			Dst:        "0xa647ff3c36cfab592509e13860ab8c4f28781a66",
+			Value:      "0x5",
+			GasPrice:   "0x0",
+			Gas:        1000000,
+			Calldata:   "0xcafebabe01",
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "sequence.json")
+	require.NoError(t, WriteMedusaFile(path, calls, MedusaOptions{}))
+
+	parsed, err := parser.ParseReproducerFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, calls, parsed)
+}
+
+func TestEncodeMedusa(t *testing.T) {
+	contractABI, err := abi.Parse([]byte(`[{"type": "function", "name": "fill", "stateMutability": "nonpayable", "outputs": [],
+		"inputs": [{"name": "orders", "type": "tuple[]", "components": [{"name": "maker", "type": "address"}, {"name": "amount", "type": "uint128"}]}]}]`))
+	require.NoError(t, err)
+
+	sender := "0x0000000000000000000000000000000000010000"
+	target := "0xa647ff3c36cfab592509e13860ab8c4f28781a66"
+	fill := types.ParsedCall{
+		FunctionName: "fill",
+		Parameters: []types.ParsedParam{{Type: "(address,uint128)[]", Elements: []types.ParsedParam{
+			{Type: "(address,uint128)", Elements: []types.ParsedParam{
+				{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+				{Type: "uint128", Value: "7"},
+			}},
+		}}},
+		Src: sender,
+		Dst: target,
+	}
+	calls := []types.ParsedCall{
+		{Parameters: []types.ParsedParam{}, Src: sender, Dst: target, HasDelay: true, DelayValue: "60"},
+		{Parameters: []types.ParsedParam{}, Src: sender, Dst: target, HasDelay: true, DelayValue: "40", HasBlockDelay: true, BlockDelayValue: "2"},
+		fill,
+		fill,
+		{Parameters: []types.ParsedParam{}, Src: sender, Dst: target, HasBlockDelay: true, BlockDelayValue: "5"},
+	}
+
+	sequence, err := EncodeMedusa(calls, MedusaOptions{ABI: contractABI})
+	require.NoError(t, err)
+
+	// Pure delays are added to the next call, trailing ones are dropped
+	require.Len(t, sequence, 2)
+	assert.Equal(t, uint64(100), sequence[0].BlockTimestampDelay)
+	assert.Equal(t, uint64(2), sequence[0].BlockNumberDelay)
+	assert.Equal(t, uint64(0), sequence[1].BlockTimestampDelay)
+	assert.Equal(t, uint64(0), sequence[0].Call.Nonce)
+	assert.Equal(t, uint64(1), sequence[1].Call.Nonce)
+	assert.Equal(t, int64(12500000), sequence[0].Call.GasLimit)
+
+	// Tuples are keyed by field name
+	require.NotNil(t, sequence[0].Call.DataAbiValues)
+	assert.Equal(t, "fill((address,uint128)[])", sequence[0].Call.DataAbiValues.MethodSignature)
+	values, err := json.Marshal(sequence[0].Call.DataAbiValues.InputValues)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[[{"maker": "0x000000000000000000000000000000000000dEaD", "amount": "7"}]]`, string(values))
+
+	// The data decodes to the same call
+	calldata, err := hex.DecodeString(strings.TrimPrefix(sequence[0].Call.Data, "0x"))
+	require.NoError(t, err)
+	method, params, err := contractABI.DecodeCall(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, "fill", method.Name)
+	assert.Equal(t, fill.Parameters, params)
+
+	// Without the field names only the data is kept
+	sequence, err = EncodeMedusa([]types.ParsedCall{fill}, MedusaOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, sequence[0].Call.DataAbiValues)
+	assert.NotEmpty(t, sequence[0].Call.Data)
+
+	// The target option rewrites the address of every call
+	echidnaCall := fill
+	echidnaCall.Dst = "0x00a329c0648769A73afAc7F9381E08FB43dBEA72"
+	sequence, err = EncodeMedusa([]types.ParsedCall{echidnaCall, fill}, MedusaOptions{Target: DefaultMedusaTarget})
+	require.NoError(t, err)
+	for _, element := range sequence {
+		assert.Equal(t, DefaultMedusaTarget, element.Call.To)
+	}
+	assert.Equal(t, "0x00a329c0648769A73afAc7F9381E08FB43dBEA72", echidnaCall.Dst)
+
+	_, err = EncodeMedusa([]types.ParsedCall{{Kind: types.CallKindCreate, Calldata: "0x6080", Src: sender, Dst: target}}, MedusaOptions{})
+	assert.ErrorContains(t, err, "cannot deploy contracts")
+}
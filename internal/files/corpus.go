@@ -0,0 +1,65 @@
+package files
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+// Origins of the sequences of an Echidna corpus directory, named after its subfolders
+const (
+	OriginCoverage    = "coverage"    // Sequences that reached new coverage, kept as regression seeds
+	OriginReproducers = "reproducers" // Sequences that broke a property
+)
+
+// CorpusOrigins lists the corpus subfolders that are converted, in output order
+var CorpusOrigins = []string{OriginReproducers, OriginCoverage}
+
+// DiscoverCorpus collects the sequences of an Echidna corpus directory from its coverage
+// and reproducers subfolders, labelled with their origin. The time range and glob filters
+// of options apply, with paths relative to the corpus directory (e.g. coverage/123.txt);
+// the selection mode does not, as every sequence of the corpus is a candidate.
+func DiscoverCorpus(corpusDir string, options DiscoverOptions) ([]FileInfo, error) {
+	if err := validatePatterns(options.Include, options.Exclude); err != nil {
+		return nil, err
+	}
+
+	var selected []FileInfo
+	found := false
+	for _, origin := range CorpusOrigins {
+		dir := filepath.Join(corpusDir, origin)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			logger.Debug("corpus folder not found", "dir", dir)
+			continue
+		}
+		found = true
+
+		candidates, err := collectReproducerFiles(dir, options.Recursive)
+		if err != nil {
+			return nil, err
+		}
+		filtered := filterFiles(corpusDir, candidates, options)
+		sort.Slice(filtered, func(i, j int) bool {
+			return filtered[i].Path < filtered[j].Path
+		})
+		for i := range filtered {
+			filtered[i].Origin = origin
+		}
+
+		logger.Event(slog.LevelInfo, fmt.Sprintf("Found %d of %d sequences in %s", len(filtered), len(candidates), dir),
+			"found corpus sequences", "dir", dir, "origin", origin, "selected", len(filtered), "candidates", len(candidates))
+		selected = append(selected, filtered...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s is not an Echidna corpus directory: no %s or %s folder", corpusDir, OriginCoverage, OriginReproducers)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no reproducer files found in corpus directory: %s", corpusDir)
+	}
+	return selected, nil
+}
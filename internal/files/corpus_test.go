@@ -0,0 +1,42 @@
+package files
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverCorpus(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeReproducer(t, filepath.Join(dir, "coverage", "2.txt"), now)
+	writeReproducer(t, filepath.Join(dir, "coverage", "1.txt"), now)
+	writeReproducer(t, filepath.Join(dir, "reproducers", "3.txt"), now)
+	writeReproducer(t, filepath.Join(dir, "reproducers-unshrunk", "3.txt"), now)
+
+	corpus, err := DiscoverCorpus(dir, DiscoverOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reproducers/3.txt", "coverage/1.txt", "coverage/2.txt"}, relativeNames(t, dir, corpus))
+	assert.Equal(t, []string{OriginReproducers, OriginCoverage, OriginCoverage},
+		[]string{corpus[0].Origin, corpus[1].Origin, corpus[2].Origin})
+
+	// Filters match paths relative to the corpus directory
+	corpus, err = DiscoverCorpus(dir, DiscoverOptions{Exclude: []string{"coverage/1.txt"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reproducers/3.txt", "coverage/2.txt"}, relativeNames(t, dir, corpus))
+}
+
+func TestDiscoverCorpus_NotACorpus(t *testing.T) {
+	dir := t.TempDir()
+	writeReproducer(t, filepath.Join(dir, "1.txt"), time.Now())
+
+	_, err := DiscoverCorpus(dir, DiscoverOptions{})
+	assert.ErrorContains(t, err, "is not an Echidna corpus directory")
+
+	writeReproducer(t, filepath.Join(dir, "coverage", "notes.md"), time.Now())
+	_, err = DiscoverCorpus(dir, DiscoverOptions{})
+	assert.ErrorContains(t, err, "no reproducer files found in corpus directory")
+}
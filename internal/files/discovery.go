@@ -2,21 +2,66 @@ package files
 
 import (
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+// Discovery modes selecting which reproducer files of a directory are processed
+const (
+	ModeNewest = "newest" // The most recent group of files written together
+	ModeAll    = "all"    // Every file that passes the filters
 )
 
+// StdinPath is the input path that reads a single reproducer from standard input
+const StdinPath = "-"
+
+// DefaultGroupWindow is the largest gap between two files written in the same group
+const DefaultGroupWindow = time.Minute
+
 // FileInfo holds file information for processing
 type FileInfo struct {
 	Path    string
 	ModTime time.Time
+	Origin  string // Corpus folder the file was found in, e.g. coverage (empty outside corpus directories)
+}
+
+// DiscoverOptions controls how reproducer files are selected from a directory
+type DiscoverOptions struct {
+	// Mode is ModeNewest or ModeAll. When empty, a time range selects all files in
+	// range and no time range selects the newest group.
+	Mode string
+
+	// Since and Until restrict files to a modification time range (zero means unbounded)
+	Since time.Time
+	Until time.Time
+
+	// GroupWindow is the largest gap between consecutive files of one group (defaults to DefaultGroupWindow)
+	GroupWindow time.Duration
+
+	// Include and Exclude are glob patterns matched against the file name and the path relative to the directory
+	Include []string
+	Exclude []string
+
+	// Recursive also searches subdirectories
+	Recursive bool
 }
 
 // DiscoverReplayFiles resolves input path to a list of files to process
-func DiscoverReplayFiles(inputPath string) ([]FileInfo, error) {
+func DiscoverReplayFiles(inputPath string, options DiscoverOptions) ([]FileInfo, error) {
+	if inputPath == StdinPath {
+		return []FileInfo{{
+			Path:    StdinPath,
+			ModTime: time.Now(),
+		}}, nil
+	}
+
 	info, err := os.Stat(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("path does not exist: %s", inputPath)
@@ -29,78 +74,229 @@ func DiscoverReplayFiles(inputPath string) ([]FileInfo, error) {
 		}}, nil
 	}
 
-	return findNewestTxtGroup(inputPath)
+	return selectFromDirectory(inputPath, options)
 }
 
-// findNewestTxtGroup finds .txt files grouped by creation time and returns the newest group
-func findNewestTxtGroup(dirPath string) ([]FileInfo, error) {
-	files, err := os.ReadDir(dirPath)
+// selectFromDirectory collects the reproducer files (.txt or .json) of a directory,
+// filters them and selects the files of the requested mode
+func selectFromDirectory(dirPath string, options DiscoverOptions) ([]FileInfo, error) {
+	if err := validatePatterns(options.Include, options.Exclude); err != nil {
+		return nil, err
+	}
+
+	mode := options.Mode
+	if mode == "" {
+		mode = ModeNewest
+		if !options.Since.IsZero() || !options.Until.IsZero() {
+			mode = ModeAll
+		}
+	}
+	if mode != ModeNewest && mode != ModeAll {
+		return nil, fmt.Errorf("unknown selection mode %q (expected %q or %q)", mode, ModeNewest, ModeAll)
+	}
+
+	window := options.GroupWindow
+	if window <= 0 {
+		window = DefaultGroupWindow
+	}
+
+	candidates, err := collectReproducerFiles(dirPath, options.Recursive)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no .txt or .json files found in directory: %s", dirPath)
 	}
 
-	txtFiles := collectTxtFiles(dirPath, files)
-	if len(txtFiles) == 0 {
-		return nil, fmt.Errorf("no .txt files found in directory: %s", dirPath)
+	filtered := filterFiles(dirPath, candidates, options)
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("none of the %d reproducer files in %s match the time range and patterns", len(candidates), dirPath)
 	}
 
-	groups := groupByCreationTime(txtFiles)
-	newestGroup := selectNewestGroup(groups)
+	selected := filtered
+	description := "all files"
+	if mode == ModeNewest {
+		groups := groupByModTime(filtered, window)
+		selected = groups[len(groups)-1]
+		description = fmt.Sprintf("newest of %d groups (written within %s of each other, ending %s)",
+			len(groups), window, selected[len(selected)-1].ModTime.Format("2006-01-02 15:04:05"))
+	}
+	if !options.Since.IsZero() || !options.Until.IsZero() {
+		description += ", " + describeRange(options.Since, options.Until)
+	}
 
-	// Sort files within the group by name for consistent ordering
-	sort.Slice(newestGroup, func(i, j int) bool {
-		return filepath.Base(newestGroup[i].Path) < filepath.Base(newestGroup[j].Path)
+	// Sort selected files by path for consistent ordering
+	sort.Slice(selected, func(i, j int) bool {
+		return relativePath(dirPath, selected[i].Path) < relativePath(dirPath, selected[j].Path)
 	})
 
-	fmt.Printf("\nFound %d groups of .txt files, selecting newest group with %d files (created at %s)\n",
-		len(groups), len(newestGroup), newestGroup[0].ModTime.Truncate(time.Minute).Format("2006-01-02 15:04"))
+	logger.Event(slog.LevelInfo, fmt.Sprintf("\nSelected %d of %d reproducer files: %s", len(selected), len(candidates), description),
+		"selected reproducer files", "dir", dirPath, "selected", len(selected), "candidates", len(candidates), "selection", description)
+	for _, file := range selected {
+		logger.Printf("  - %s\n", relativePath(dirPath, file.Path))
+	}
+	logger.Println()
 
-	return newestGroup, nil
+	return selected, nil
 }
 
-// collectTxtFiles gathers all .txt files from the directory
-func collectTxtFiles(dirPath string, files []os.DirEntry) []FileInfo {
-	var txtFiles []FileInfo
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(strings.ToLower(file.Name()), ".txt") {
-			fullPath := filepath.Join(dirPath, file.Name())
-			if info, err := os.Stat(fullPath); err == nil {
-				txtFiles = append(txtFiles, FileInfo{
-					Path:    fullPath,
-					ModTime: info.ModTime(),
-				})
+// collectReproducerFiles gathers all reproducer files from the directory, and its subdirectories when recursive
+func collectReproducerFiles(dirPath string, recursive bool) ([]FileInfo, error) {
+	var found []FileInfo
+
+	err := filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dirPath && !recursive {
+				return filepath.SkipDir
 			}
+			return nil
+		}
+		if !IsReproducerFile(entry.Name()) {
+			return nil
 		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		found = append(found, FileInfo{
+			Path:    path,
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	return txtFiles
+	return found, nil
 }
 
-// groupByCreationTime groups files by creation time (truncated to the minute)
-func groupByCreationTime(files []FileInfo) map[string][]FileInfo {
-	groups := make(map[string][]FileInfo)
+// filterFiles keeps the files inside the time range that match the glob patterns
+func filterFiles(dirPath string, candidates []FileInfo, options DiscoverOptions) []FileInfo {
+	var result []FileInfo
+	for _, file := range candidates {
+		rel := relativePath(dirPath, file.Path)
+		if !options.Since.IsZero() && file.ModTime.Before(options.Since) {
+			logger.Debug("skipped file written before --since", "file", rel)
+			continue
+		}
+		if !options.Until.IsZero() && file.ModTime.After(options.Until) {
+			logger.Debug("skipped file written after --until", "file", rel)
+			continue
+		}
 
-	for _, file := range files {
-		timeKey := file.ModTime.Truncate(time.Minute).Format("2006-01-02 15:04")
-		groups[timeKey] = append(groups[timeKey], file)
+		if len(options.Include) > 0 && !matchesAny(options.Include, rel) {
+			logger.Debug("skipped file matching no --include pattern", "file", rel)
+			continue
+		}
+		if matchesAny(options.Exclude, rel) {
+			logger.Debug("skipped file matching an --exclude pattern", "file", rel)
+			continue
+		}
+
+		result = append(result, file)
 	}
+	return result
+}
+
+// groupByModTime sorts files by modification time and splits them into groups wherever
+// two consecutive files are more than window apart. Unlike fixed buckets, a group written
+// over several minutes stays together as long as the writes keep coming.
+func groupByModTime(files []FileInfo, window time.Duration) [][]FileInfo {
+	sorted := append([]FileInfo(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModTime.Before(sorted[j].ModTime)
+	})
 
+	var groups [][]FileInfo
+	for i, file := range sorted {
+		if i == 0 || file.ModTime.Sub(sorted[i-1].ModTime) > window {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], file)
+	}
 	return groups
 }
 
-// selectNewestGroup finds the group with the most recent creation time
-func selectNewestGroup(groups map[string][]FileInfo) []FileInfo {
-	var newestTime time.Time
-	var newestGroup []FileInfo
+// matchesAny reports whether a relative path or its file name matches one of the glob patterns
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, group := range groups {
-		groupTime := group[0].ModTime.Truncate(time.Minute)
-		if newestTime.IsZero() || groupTime.After(newestTime) {
-			newestTime = groupTime
-			newestGroup = group
+// validatePatterns rejects malformed glob patterns up front
+func validatePatterns(patternLists ...[]string) error {
+	for _, patterns := range patternLists {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
 		}
 	}
+	return nil
+}
+
+// relativePath returns path relative to the input directory, falling back to path itself
+func relativePath(dirPath, path string) string {
+	if rel, err := filepath.Rel(dirPath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// describeRange formats a modification time range for display
+func describeRange(since, until time.Time) string {
+	const layout = "2006-01-02 15:04:05"
+	switch {
+	case since.IsZero():
+		return "modified until " + until.Format(layout)
+	case until.IsZero():
+		return "modified since " + since.Format(layout)
+	default:
+		return fmt.Sprintf("modified between %s and %s", since.Format(layout), until.Format(layout))
+	}
+}
+
+// ParseTime parses a --since/--until value: an RFC 3339 timestamp, a local date
+// ("2006-01-02") or date and time ("2006-01-02 15:04[:05]"), or a duration such
+// as "90m" meaning that long before now
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (expected a duration like 2h, a date like 2006-01-02 or an RFC 3339 timestamp)", value)
+}
 
-	return newestGroup
+// IsReproducerFile reports whether a file name looks like an Echidna reproducer (.txt)
+// or a Medusa call sequence (.json)
+func IsReproducerFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".txt") || strings.HasSuffix(lower, ".json")
 }
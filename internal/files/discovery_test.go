@@ -0,0 +1,120 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeReproducer creates a reproducer file with the given modification time
+func writeReproducer(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// relativeNames returns the relative paths of discovered files
+func relativeNames(t *testing.T, dir string, files []FileInfo) []string {
+	var names []string
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file.Path)
+		require.NoError(t, err)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	return names
+}
+
+func TestDiscoverReplayFiles_Modes(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+
+	// An old group, then a group written over three minutes with gaps below the window
+	writeReproducer(t, filepath.Join(dir, "old.txt"), start)
+	writeReproducer(t, filepath.Join(dir, "a.txt"), start.Add(time.Hour))
+	writeReproducer(t, filepath.Join(dir, "b.txt"), start.Add(time.Hour+50*time.Second))
+	writeReproducer(t, filepath.Join(dir, "c.json"), start.Add(time.Hour+100*time.Second))
+	writeReproducer(t, filepath.Join(dir, "nested", "d.txt"), start.Add(time.Hour+100*time.Second))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("ignored"), 0644))
+
+	tests := []struct {
+		name     string
+		options  DiscoverOptions
+		expected []string
+	}{
+		{
+			name:     "newest group spans several minutes",
+			options:  DiscoverOptions{},
+			expected: []string{"a.txt", "b.txt", "c.json"},
+		},
+		{
+			name:     "smaller window splits the group",
+			options:  DiscoverOptions{GroupWindow: 30 * time.Second},
+			expected: []string{"c.json"},
+		},
+		{
+			name:     "all files",
+			options:  DiscoverOptions{Mode: ModeAll},
+			expected: []string{"a.txt", "b.txt", "c.json", "old.txt"},
+		},
+		{
+			name:     "recursive",
+			options:  DiscoverOptions{Mode: ModeAll, Recursive: true},
+			expected: []string{"a.txt", "b.txt", "c.json", "nested/d.txt", "old.txt"},
+		},
+		{
+			name:     "time range selects all files in range",
+			options:  DiscoverOptions{Since: start.Add(-time.Minute), Until: start.Add(time.Hour + time.Minute)},
+			expected: []string{"a.txt", "b.txt", "old.txt"},
+		},
+		{
+			name:     "glob filters",
+			options:  DiscoverOptions{Mode: ModeAll, Recursive: true, Include: []string{"*.txt"}, Exclude: []string{"nested/*"}},
+			expected: []string{"a.txt", "b.txt", "old.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := DiscoverReplayFiles(dir, tt.options)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, relativeNames(t, dir, files))
+		})
+	}
+
+	_, err := DiscoverReplayFiles(dir, DiscoverOptions{Include: []string{"*.sol"}})
+	assert.Error(t, err)
+
+	_, err = DiscoverReplayFiles(dir, DiscoverOptions{Mode: "oldest"})
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"", time.Time{}},
+		{"2h", now.Add(-2 * time.Hour)},
+		{"2024-04-30", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{"2024-04-30 08:15", time.Date(2024, 4, 30, 8, 15, 0, 0, time.UTC)},
+		{"2024-04-30T08:15:00+02:00", time.Date(2024, 4, 30, 6, 15, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, err := ParseTime(tt.value, now)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), "expected %s, got %s", tt.expected, parsed)
+		})
+	}
+
+	_, err := ParseTime("yesterday", now)
+	assert.Error(t, err)
+}
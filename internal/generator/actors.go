@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// defaultActors are Echidna's default senders and the constants Enigma Dark suites declare for them
+var defaultActors = []Actor{
+	{Name: "USER1", Address: "0x0000000000000000000000000000000000010000", Default: true},
+	{Name: "USER2", Address: "0x0000000000000000000000000000000000020000", Default: true},
+	{Name: "USER3", Address: "0x0000000000000000000000000000000000030000", Default: true},
+}
+
+// identifierPattern matches valid Solidity identifiers
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Actor maps a sender address to the constant used for it in generated tests
+type Actor struct {
+	Name      string // Constant name, e.g. USER1
+	Address   string // Checksummed address
+	Default   bool   // One of the default USER1-USER3 constants
+	Generated bool   // Created for a sender missing from the configured mapping
+}
+
+// ActorTable resolves sender addresses to actor constants
+type ActorTable struct {
+	actors    []Actor
+	byAddress map[string]int
+	names     map[string]bool
+}
+
+// NewActorTable creates an actor table from the default senders and a configured
+// address -> constant name mapping. Configured entries override the defaults.
+func NewActorTable(mapping map[string]string) (*ActorTable, error) {
+	table := &ActorTable{
+		byAddress: make(map[string]int),
+		names:     make(map[string]bool),
+	}
+
+	var configured []Actor
+	configuredAddresses := make(map[string]bool)
+	configuredNames := make(map[string]bool)
+	for address, name := range mapping {
+		normalized, err := utils.NormalizeAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address for actor %s: %w", name, err)
+		}
+		if !identifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("actor name %q for %s is not a valid Solidity identifier", name, address)
+		}
+
+		configured = append(configured, Actor{Name: name, Address: normalized, Default: isDefaultName(name)})
+		configuredAddresses[normalized] = true
+		configuredNames[name] = true
+	}
+	// Sort configured actors so the generated constants are deterministic
+	sort.Slice(configured, func(i, j int) bool {
+		return configured[i].Address < configured[j].Address
+	})
+
+	for _, actor := range defaultActors {
+		if configuredAddresses[actor.Address] || configuredNames[actor.Name] {
+			continue
+		}
+		if err := table.add(actor); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, actor := range configured {
+		if err := table.add(actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return table, nil
+}
+
+// Resolve returns the constant for a sender address, generating a new one for unmapped senders
+func (t *ActorTable) Resolve(address string) (string, error) {
+	normalized, err := utils.NormalizeAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender: %w", err)
+	}
+
+	if index, ok := t.byAddress[normalized]; ok {
+		return t.actors[index].Name, nil
+	}
+
+	name := t.nextGeneratedName()
+	if err := t.add(Actor{Name: name, Address: normalized, Generated: true}); err != nil {
+		return "", err
+	}
+	logger.Debug("generated actor for unmapped sender", "sender", normalized, "actor", name)
+
+	return name, nil
+}
+
+// Declare registers an actor constant that is already declared elsewhere, such as in a
+// contract being appended to, so that its sender resolves to it. Constants whose name or
+// address is already known are ignored.
+func (t *ActorTable) Declare(name, address string) {
+	normalized, err := utils.NormalizeAddress(address)
+	if err != nil || t.names[name] {
+		return
+	}
+	if _, ok := t.byAddress[normalized]; ok {
+		return
+	}
+	t.add(Actor{Name: name, Address: normalized, Default: isDefaultName(name)})
+}
+
+// Address returns the address of the actor constant called name
+func (t *ActorTable) Address(name string) (string, bool) {
+	for _, actor := range t.actors {
+		if actor.Name == name {
+			return actor.Address, true
+		}
+	}
+	return "", false
+}
+
+// Actors returns all actors, including those generated for unmapped senders
+func (t *ActorTable) Actors() []Actor {
+	return append([]Actor(nil), t.actors...)
+}
+
+// Unmapped returns the actors generated for senders missing from the configured mapping
+func (t *ActorTable) Unmapped() []Actor {
+	var result []Actor
+	for _, actor := range t.actors {
+		if actor.Generated {
+			result = append(result, actor)
+		}
+	}
+	return result
+}
+
+// add registers a new actor, rejecting duplicate addresses and names
+func (t *ActorTable) add(actor Actor) error {
+	normalized, err := utils.NormalizeAddress(actor.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address for actor %s: %w", actor.Name, err)
+	}
+
+	if _, ok := t.byAddress[normalized]; ok {
+		return fmt.Errorf("address %s is mapped to more than one actor", normalized)
+	}
+	if t.names[actor.Name] {
+		return fmt.Errorf("actor name %s is mapped to more than one address", actor.Name)
+	}
+
+	checksummed, err := utils.ChecksumAddress(normalized)
+	if err != nil {
+		return err
+	}
+	actor.Address = checksummed
+
+	t.byAddress[normalized] = len(t.actors)
+	t.actors = append(t.actors, actor)
+	t.names[actor.Name] = true
+
+	return nil
+}
+
+// nextGeneratedName returns the USERn constant numbered after the default names and the
+// highest one taken. Names freed by the configuration are not reused, as test suites
+// declare the defaults themselves (e.g. USER1 in an Enigma Dark Setup).
+func (t *ActorTable) nextGeneratedName() string {
+	next := len(defaultActors) + 1
+	for name := range t.names {
+		digits, ok := strings.CutPrefix(name, "USER")
+		if n, err := strconv.Atoi(digits); ok && err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("USER%d", next)
+}
+
+// isDefaultName reports whether a constant is one of the default USER1-USER3 names
+func isDefaultName(name string) bool {
+	for _, actor := range defaultActors {
+		if actor.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActorTable_GeneratedNames(t *testing.T) {
+	// USER1 is remapped, but the default constant is still declared by the test suite
+	actors, err := NewActorTable(map[string]string{"0x10000": "ALICE"})
+	require.NoError(t, err)
+
+	name, err := actors.Resolve("0x10000")
+	require.NoError(t, err)
+	assert.Equal(t, "ALICE", name)
+
+	name, err = actors.Resolve("0x40000")
+	require.NoError(t, err)
+	assert.Equal(t, "USER4", name)
+
+	// Generated names follow the highest configured one
+	actors, err = NewActorTable(map[string]string{"0xdead": "USER1", "0xbeef": "USER7"})
+	require.NoError(t, err)
+
+	name, err = actors.Resolve("0x10000")
+	require.NoError(t, err)
+	assert.Equal(t, "USER8", name)
+
+	name, err = actors.Resolve("0x50000")
+	require.NoError(t, err)
+	assert.Equal(t, "USER9", name)
+
+	address, ok := actors.Address("USER1")
+	require.True(t, ok)
+	assert.Equal(t, "0x000000000000000000000000000000000000dEaD", address)
+}
@@ -0,0 +1,571 @@
+package generator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// DefaultExportTarget is the address Echidna deploys the tested contract at, used for tests
+// that do not record the target of their transactions
+const DefaultExportTarget = "0x00a329c0648769A73afAc7F9381E08FB43dBEA72"
+
+// DefaultExportGas is the gas limit Echidna gives each transaction
+const DefaultExportGas = 12500000
+
+// checkComment precedes the property check that ends a generated test
+const checkComment = "Check the property broken by the reproducer"
+
+// pureDelayComment follows the delays of a transaction without a call
+const pureDelayComment = "Delay without a call"
+
+var (
+	setUpActorStatement   = regexp.MustCompile(`^_setUpActor\((.+)\)$`)
+	actorAndDelay         = regexp.MustCompile(`^_setUpActorAndDelay\((.+),\s*(\d+)\)$`)
+	delayStatement        = regexp.MustCompile(`^_delay\((\d+)\)$`)
+	blockDelayStatement   = regexp.MustCompile(`^_delayBlocks\((\d+)\)$`)
+	functionCallStatement = regexp.MustCompile(`^Tester\.(\w+)\s*(?:\{\s*value:\s*(\d+)\s*\})?\s*\(([\s\S]*)\)$`)
+	rawCallStatement      = regexp.MustCompile(`^address\(Tester\)\.call\s*(?:\{\s*value:\s*(\d+)\s*\})?\s*\(hex"([0-9a-fA-F]*)"\)$`)
+	arrayDeclaration      = regexp.MustCompile(`^(\S+)\s+memory\s+(\w+)\s*=\s*new\s+\S+\((\d+)\)$`)
+	arrayAssignment       = regexp.MustCompile(`^(\w+)\[(\d+)\]\s*=\s*([\s\S]+)$`)
+	codeDeclaration       = regexp.MustCompile(`^bytes\s+memory\s+(\w+)\s*=\s*hex"([0-9a-fA-F]*)"$`)
+	createAssembly        = regexp.MustCompile(`^assembly\s*\{\s*\w+\s*:=\s*create\((\d+),\s*add\((\w+),`)
+	newStatement          = regexp.MustCompile(`^(\w+)\s+\w+\s*=\s*new\s+\w+`)
+	ignoredStatement      = regexp.MustCompile(`^(vm\.deal\(|require\(|address\s+\w+$)`)
+	transactionComment    = regexp.MustCompile(`^Transaction(s?): target (0x[0-9a-fA-F]{40}), gas (\d+), gas price (\d+)$`)
+
+	elementaryType  = regexp.MustCompile(`^(u?int\d*|address|bool|string|bytes\d*)$`)
+	castExpression  = regexp.MustCompile(`^(u?int\d*|address|bytes\d+|string)\(([\s\S]+)\)$`)
+	structLiteral   = regexp.MustCompile(`^([A-Za-z_$][\w$]*)\(([\s\S]*)\)$`)
+	hexLiteral      = regexp.MustCompile(`^hex"([0-9a-fA-F]*)"$`)
+	addressLiteral  = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	decimalLiteral  = regexp.MustCompile(`^-?\d+$`)
+	identifierToken = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+)
+
+// ExportOptions controls how replay tests are turned back into call sequences
+type ExportOptions struct {
+	Actors *ActorTable // Actor constants, extended with those the contract declares (defaults when nil)
+	Target string      // Address the calls are sent to when a test does not record it, DefaultExportTarget when empty
+	Gas    int64       // Gas limit of each transaction when a test does not record it, DefaultExportGas when 0
+}
+
+// ExportedTest is the call sequence replayed by a test function
+type ExportedTest struct {
+	Name  string
+	Calls []types.ParsedCall
+}
+
+// ExportTests parses replay test functions of a runes-generated contract back into the
+// call sequences they replay: actor changes, delays, function calls with their typed
+// arguments, raw calls and deployments from creation code, with the target, gas and gas
+// price the generated test records. names selects the functions, all test functions when
+// empty. In tests without the comments marking pure delays, a pure delay directly followed
+// by a call of the same sender cannot be told apart from a delayed call, so it is exported
+// as one.
+func ExportTests(source string, names []string, options ExportOptions) ([]ExportedTest, error) {
+	if options.Actors == nil {
+		actors, err := NewActorTable(nil)
+		if err != nil {
+			return nil, err
+		}
+		options.Actors = actors
+	}
+	for _, match := range actorConstant.FindAllStringSubmatch(source, -1) {
+		options.Actors.Declare(match[1], match[2])
+	}
+	if options.Target == "" {
+		options.Target = DefaultExportTarget
+	}
+	if options.Gas == 0 {
+		options.Gas = DefaultExportGas
+	}
+
+	functions, _, err := scanContract(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []ExportedTest
+	found := make(map[string]bool)
+	for _, function := range functions {
+		selected := strings.HasPrefix(function.name, "test")
+		if len(names) > 0 {
+			selected = slices.Contains(names, function.name)
+		}
+		if !selected {
+			continue
+		}
+		found[function.name] = true
+
+		calls, err := exportFunction(function.body, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", function.name, err)
+		}
+		tests = append(tests, ExportedTest{Name: function.name, Calls: calls})
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("test function %s not found", name)
+		}
+	}
+	if len(tests) == 0 {
+		return nil, fmt.Errorf("no test functions found")
+	}
+	return tests, nil
+}
+
+// replayState is the transaction being rebuilt while the statements of a test are read
+type replayState struct {
+	options      ExportOptions
+	calls        []types.ParsedCall
+	sender       string
+	delay        string // Pending time delay, empty when none
+	blocks       string // Pending block delay, empty when none
+	transactions exportTransaction
+	transaction  *exportTransaction // Target, gas and gas price of the next transaction only
+	arrays       map[string][]string
+	codes        map[string]string
+}
+
+// exportTransaction is the target, gas and gas price of a transaction
+type exportTransaction struct {
+	target   string
+	gas      int64
+	gasPrice string
+}
+
+// exportFunction rebuilds the call sequence of a test function body
+func exportFunction(body string, options ExportOptions) ([]types.ParsedCall, error) {
+	statements, err := splitStatements(body)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &replayState{
+		options:      options,
+		transactions: exportTransaction{target: options.Target, gas: options.Gas, gasPrice: "0x0"},
+		arrays:       make(map[string][]string),
+		codes:        make(map[string]string),
+	}
+	for _, statement := range statements {
+		if statement.comment {
+			if statement.text == checkComment {
+				break
+			}
+			state.applyComment(statement.text)
+			continue
+		}
+		if err := state.apply(statement.text); err != nil {
+			return nil, fmt.Errorf("%q: %w", statement.text, err)
+		}
+	}
+	state.flushDelay()
+	return state.calls, nil
+}
+
+// applyComment updates the state with a comment the templates write
+func (s *replayState) applyComment(comment string) {
+	if note, ok := strings.CutPrefix(comment, "Skipped transaction: "); ok {
+		logger.Warn(fmt.Sprintf("transaction skipped when the test was generated cannot be exported: %s", note))
+		return
+	}
+	if comment == pureDelayComment {
+		s.calls = append(s.calls, s.newCall("0"))
+		return
+	}
+
+	match := transactionComment.FindStringSubmatch(comment)
+	if match == nil {
+		return
+	}
+	transaction := s.transactions
+	transaction.target = match[2]
+	if gas, err := strconv.ParseInt(match[3], 10, 64); err == nil && gas > 0 {
+		transaction.gas = gas
+	}
+	if gasPrice, ok := new(big.Int).SetString(match[4], 10); ok {
+		transaction.gasPrice = fmt.Sprintf("0x%x", gasPrice)
+	}
+
+	// The plural form describes all transactions of the test
+	if match[1] == "s" {
+		s.transactions = transaction
+	} else {
+		s.transaction = &transaction
+	}
+}
+
+// apply updates the state with one statement
+func (s *replayState) apply(statement string) error {
+	switch {
+	case setUpActorStatement.MatchString(statement):
+		return s.setSender(setUpActorStatement.FindStringSubmatch(statement)[1])
+	case actorAndDelay.MatchString(statement):
+		match := actorAndDelay.FindStringSubmatch(statement)
+		if err := s.setSender(match[1]); err != nil {
+			return err
+		}
+		s.addDelay(match[2])
+	case delayStatement.MatchString(statement):
+		s.addDelay(delayStatement.FindStringSubmatch(statement)[1])
+	case blockDelayStatement.MatchString(statement):
+		if s.blocks != "" {
+			s.flushDelay()
+		}
+		s.blocks = blockDelayStatement.FindStringSubmatch(statement)[1]
+	case arrayDeclaration.MatchString(statement):
+		match := arrayDeclaration.FindStringSubmatch(statement)
+		length, _ := strconv.Atoi(match[3])
+		s.arrays[match[2]] = append([]string{match[1]}, make([]string, length)...)
+	case arrayAssignment.MatchString(statement):
+		match := arrayAssignment.FindStringSubmatch(statement)
+		array, ok := s.arrays[match[1]]
+		index, _ := strconv.Atoi(match[2])
+		if !ok || index+1 >= len(array) {
+			return fmt.Errorf("assignment to an undeclared array element")
+		}
+		array[index+1] = match[3]
+	case codeDeclaration.MatchString(statement):
+		match := codeDeclaration.FindStringSubmatch(statement)
+		s.codes[match[1]] = strings.ToLower(match[2])
+	case createAssembly.MatchString(statement):
+		match := createAssembly.FindStringSubmatch(statement)
+		code, ok := s.codes[match[2]]
+		if !ok {
+			return fmt.Errorf("unknown creation code %s", match[2])
+		}
+		call := s.newCall(match[1])
+		call.Kind = types.CallKindCreate
+		call.Calldata = "0x" + code
+		s.calls = append(s.calls, call)
+	case rawCallStatement.MatchString(statement):
+		match := rawCallStatement.FindStringSubmatch(statement)
+		call := s.newCall(match[1])
+		call.Kind = types.CallKindRaw
+		call.Calldata = "0x" + strings.ToLower(match[2])
+		s.calls = append(s.calls, call)
+	case functionCallStatement.MatchString(statement):
+		match := functionCallStatement.FindStringSubmatch(statement)
+		params, err := s.parseArguments(match[3])
+		if err != nil {
+			return err
+		}
+		call := s.newCall(match[2])
+		call.FunctionName = match[1]
+		call.Parameters = params
+		s.calls = append(s.calls, call)
+	case newStatement.MatchString(statement):
+		return fmt.Errorf("deployments with new cannot be exported, as the creation code of %s is unknown", newStatement.FindStringSubmatch(statement)[1])
+	case ignoredStatement.MatchString(statement):
+	default:
+		logger.Warn(fmt.Sprintf("skipped statement that is not part of a replay: %s", statement))
+	}
+	return nil
+}
+
+// setSender switches the sender of the next transactions. Delays written before the switch
+// belonged to a pure delay of the previous sender.
+func (s *replayState) setSender(expression string) error {
+	address, err := s.resolveActor(expression)
+	if err != nil {
+		return err
+	}
+	if address != s.sender {
+		s.flushDelay()
+		s.sender = address
+	}
+	return nil
+}
+
+// resolveActor returns the address of an actor constant or address literal
+func (s *replayState) resolveActor(expression string) (string, error) {
+	expression = strings.TrimSpace(expression)
+	if inner, ok := strings.CutPrefix(expression, "address("); ok {
+		expression = strings.TrimSuffix(inner, ")")
+	}
+	if addressLiteral.MatchString(expression) {
+		return utils.ChecksumAddress(expression)
+	}
+	if address, ok := s.options.Actors.Address(expression); ok {
+		return address, nil
+	}
+	return "", fmt.Errorf("unknown actor %s (declare it as an address constant or add it to 'actors' in .runes.yaml)", expression)
+}
+
+// addDelay records a time delay, which starts a new transaction when one is already pending
+func (s *replayState) addDelay(seconds string) {
+	if s.delay != "" || s.blocks != "" {
+		s.flushDelay()
+	}
+	s.delay = seconds
+}
+
+// flushDelay turns pending delays into a pure delay transaction (Echidna's NoCall)
+func (s *replayState) flushDelay() {
+	if s.delay == "" && s.blocks == "" {
+		return
+	}
+	s.calls = append(s.calls, s.newCall("0"))
+}
+
+// newCall starts a transaction of the current sender, taking the pending delays and
+// transaction fields
+func (s *replayState) newCall(value string) types.ParsedCall {
+	transaction := s.transactions
+	if s.transaction != nil {
+		transaction = *s.transaction
+	}
+	call := types.ParsedCall{
+		Parameters:      []types.ParsedParam{},
+		Src:             s.sender,
+		Dst:             transaction.target,
+		Gas:             transaction.gas,
+		Value:           "0x0",
+		GasPrice:        transaction.gasPrice,
+		HasDelay:        s.delay != "",
+		DelayValue:      s.delay,
+		HasBlockDelay:   s.blocks != "",
+		BlockDelayValue: s.blocks,
+	}
+	if wei, ok := new(big.Int).SetString(value, 10); ok && wei.Sign() > 0 {
+		call.Value = fmt.Sprintf("0x%x", wei)
+	}
+	s.delay, s.blocks, s.transaction = "", "", nil
+	return call
+}
+
+// parseArguments parses a comma separated list of Solidity argument expressions
+func (s *replayState) parseArguments(list string) ([]types.ParsedParam, error) {
+	expressions, err := splitExpressions(list)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []types.ParsedParam{}
+	for i, expression := range expressions {
+		param, err := s.parseExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// parseExpression parses an argument expression written by the parameter renderer: typed
+// literals, fixed array literals, struct constructors and dynamic arrays built in memory
+func (s *replayState) parseExpression(expression string) (types.ParsedParam, error) {
+	expression = strings.TrimSpace(expression)
+	switch {
+	case expression == "true" || expression == "false":
+		return types.ParsedParam{Type: "bool", Value: expression}, nil
+	case strings.HasPrefix(expression, `"`):
+		return parseStringLiteral(expression)
+	case hexLiteral.MatchString(expression):
+		return types.ParsedParam{Type: "bytes", Value: "0x" + strings.ToLower(hexLiteral.FindStringSubmatch(expression)[1])}, nil
+	case addressLiteral.MatchString(expression):
+		return types.ParsedParam{Type: "address", Value: expression}, nil
+	case decimalLiteral.MatchString(expression):
+		return types.ParsedParam{Type: "uint256", Value: expression}, nil
+	case castExpression.MatchString(expression):
+		match := castExpression.FindStringSubmatch(expression)
+		value := strings.TrimSpace(match[2])
+		if match[1] == "string" {
+			return parseStringLiteral(value)
+		}
+		if strings.HasPrefix(match[1], "bytes") {
+			value = strings.ToLower(value)
+		}
+		return types.ParsedParam{Type: match[1], Value: value}, nil
+	case strings.HasPrefix(expression, "[") && strings.HasSuffix(expression, "]"):
+		elements, err := s.parseArguments(expression[1 : len(expression)-1])
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		if len(elements) == 0 {
+			return types.ParsedParam{}, fmt.Errorf("empty fixed-size array")
+		}
+		return types.ParsedParam{Type: fmt.Sprintf("%s[%d]", elements[0].Type, len(elements)), Elements: elements}, nil
+	case structLiteral.MatchString(expression):
+		components, err := s.parseArguments(structLiteral.FindStringSubmatch(expression)[2])
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		componentTypes := make([]string, len(components))
+		for i, component := range components {
+			componentTypes[i] = component.Type
+		}
+		return types.ParsedParam{Type: "(" + strings.Join(componentTypes, ",") + ")", Elements: components}, nil
+	case identifierToken.MatchString(expression):
+		return s.parseVariable(expression)
+	default:
+		return types.ParsedParam{}, fmt.Errorf("unsupported expression %s", expression)
+	}
+}
+
+// parseStringLiteral reads a string literal, quoted or hex, back into the quoted form the
+// parsers give string values
+func parseStringLiteral(literal string) (types.ParsedParam, error) {
+	if match := hexLiteral.FindStringSubmatch(literal); match != nil {
+		content, err := hex.DecodeString(match[1])
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("invalid hex string literal: %s", literal)
+		}
+		return types.ParsedParam{Type: "string", Value: `"` + string(content) + `"`}, nil
+	}
+	content, err := strconv.Unquote(literal)
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid string literal: %s", literal)
+	}
+	return types.ParsedParam{Type: "string", Value: `"` + content + `"`}, nil
+}
+
+// parseVariable parses a dynamic array built in memory, or an actor constant
+func (s *replayState) parseVariable(name string) (types.ParsedParam, error) {
+	array, ok := s.arrays[name]
+	if !ok {
+		if address, ok := s.options.Actors.Address(name); ok {
+			return types.ParsedParam{Type: "address", Value: address}, nil
+		}
+		return types.ParsedParam{}, fmt.Errorf("unknown variable %s", name)
+	}
+
+	declType := array[0]
+	elements := []types.ParsedParam{}
+	for i, expression := range array[1:] {
+		if expression == "" {
+			return types.ParsedParam{}, fmt.Errorf("element %d of %s is never assigned", i, name)
+		}
+		element, err := s.parseExpression(expression)
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("element %d of %s: %w", i, name, err)
+		}
+		elements = append(elements, element)
+	}
+
+	// Arrays of structs are declared with the struct name, the ABI type is a tuple
+	solType := declType
+	if !elementaryType.MatchString(declType[:strings.IndexByte(declType, '[')]) {
+		if len(elements) == 0 {
+			return types.ParsedParam{}, fmt.Errorf("cannot infer the tuple type of the empty array %s", name)
+		}
+		solType = elements[0].Type + "[]"
+	}
+	return types.ParsedParam{Type: solType, Elements: elements}, nil
+}
+
+// exportStatement is a statement or a line comment of a function body
+type exportStatement struct {
+	text    string
+	comment bool
+}
+
+// splitStatements splits a function body into statements and line comments, keeping
+// assembly blocks whole. Whitespace inside statements is collapsed.
+func splitStatements(body string) ([]exportStatement, error) {
+	var statements []exportStatement
+	var current strings.Builder
+	depth := 0
+
+	emit := func() {
+		if text := normalizeBody(current.String()); text != "" {
+			statements = append(statements, exportStatement{text: text})
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case strings.HasPrefix(body[i:], "//"):
+			end := strings.IndexByte(body[i:], '\n')
+			if end < 0 {
+				end = len(body) - i
+			}
+			statements = append(statements, exportStatement{text: strings.TrimSpace(body[i+2 : i+end]), comment: true})
+			i += end
+		case strings.HasPrefix(body[i:], "/*"):
+			end := strings.Index(body[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			start := i
+			for i++; i < len(body) && body[i] != c; i++ {
+				if body[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			current.WriteString(body[start : i+1])
+		case c == '{' && depth == 0 && strings.TrimSpace(current.String()) == "assembly":
+			end := strings.IndexByte(body[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated assembly block")
+			}
+			current.WriteString(body[i : i+end+1])
+			i += end
+			emit()
+		case c == '(' || c == '[' || c == '{':
+			depth++
+			current.WriteByte(c)
+		case c == ')' || c == ']' || c == '}':
+			depth--
+			current.WriteByte(c)
+		case c == ';' && depth == 0:
+			emit()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets")
+	}
+	emit()
+	return statements, nil
+}
+
+// splitExpressions splits a comma separated expression list at its top level
+func splitExpressions(list string) ([]string, error) {
+	var expressions []string
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch c := list[i]; c {
+		case '"', '\'':
+			for i++; i < len(list) && list[i] != c; i++ {
+				if list[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				expressions = append(expressions, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %s", list)
+	}
+	if rest := strings.TrimSpace(list[start:]); rest != "" || len(expressions) > 0 {
+		expressions = append(expressions, list[start:])
+	}
+	return expressions, nil
+}
@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/encoder"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// exportReproducer covers every transaction kind the templates render, a transaction with
+// its own target, gas and gas price, and a pure delay followed by a call of the same sender.
+const exportReproducer = `[
+	{"call": {"tag": "SolCall", "contents": ["deposit", [
+		{"tag": "AbiUInt", "contents": [256, "5"]},
+		{"tag": "AbiInt", "contents": [8, "-3"]},
+		{"tag": "AbiAddress", "contents": "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496"},
+		{"tag": "AbiBool", "contents": [true]},
+		{"tag": "AbiString", "contents": "hi; (there)"},
+		{"tag": "AbiString", "contents": "say \"hi\" \\o/"},
+		{"tag": "AbiString", "contents": "line\nbreak, caf\u00e9"},
+		{"tag": "AbiBytesDynamic", "contents": "0xdeadbeef"},
+		{"tag": "AbiBytes", "contents": [4, "0x12345678"]},
+		{"tag": "AbiArrayDynamic", "contents": [{"tag": "AbiUIntType", "contents": 8}, [
+			{"tag": "AbiUInt", "contents": [8, "1"]}, {"tag": "AbiUInt", "contents": [8, "2"]}]]},
+		{"tag": "AbiArray", "contents": [2, {"tag": "AbiTupleType", "contents": [{"tag": "AbiAddressType"}, {"tag": "AbiUIntType", "contents": 256}]}, [
+			{"tag": "AbiTuple", "contents": [{"tag": "AbiAddress", "contents": "0x0000000000000000000000000000000000010000"}, {"tag": "AbiUInt", "contents": [256, "1"]}]},
+			{"tag": "AbiTuple", "contents": [{"tag": "AbiAddress", "contents": "0x0000000000000000000000000000000000020000"}, {"tag": "AbiUInt", "contents": [256, "2"]}]}]]},
+		{"tag": "AbiArrayDynamic", "contents": [{"tag": "AbiTupleType", "contents": [{"tag": "AbiUIntType", "contents": 256}, {"tag": "AbiBoolType"}]}, [
+			{"tag": "AbiTuple", "contents": [{"tag": "AbiUInt", "contents": [256, "7"]}, {"tag": "AbiBool", "contents": [false]}]}]]},
+		{"tag": "AbiArrayDynamic", "contents": [{"tag": "AbiArrayDynamicType", "contents": {"tag": "AbiUIntType", "contents": 256}}, [
+			{"tag": "AbiArrayDynamic", "contents": [{"tag": "AbiUIntType", "contents": 256}, [{"tag": "AbiUInt", "contents": [256, "9"]}]]},
+			{"tag": "AbiArrayDynamic", "contents": [{"tag": "AbiUIntType", "contents": 256}, []]}]]}
+	]]},
+	 "src": "0x0000000000000000000000000000000000010000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0x0", "delay": ["0x3c", "0x2"]},
+	{"call": {"tag": "SolCall", "contents": ["donate", []]},
+	 "src": "0x0000000000000000000000000000000000010000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0xde0b6b3a7640000", "delay": ["0x0", "0x0"]},
+	{"call": {"tag": "SolCall", "contents": ["donate", []]},
+	 "src": "0x0000000000000000000000000000000000020000", "dst": "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496",
+	 "gas": 1000000, "gasprice": "0x3b9aca00", "value": "0x0", "delay": ["0x0", "0x0"]},
+	{"call": {"tag": "NoCall"},
+	 "src": "0x0000000000000000000000000000000000010000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0x0", "delay": ["0x64", "0x0"]},
+	{"call": {"tag": "SolCalldata", "contents": "0xcafebabe01"},
+	 "src": "0x0000000000000000000000000000000000010000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0x5", "delay": ["0x0", "0x0"]},
+	{"call": {"tag": "SolCreate", "contents": "0x6080604052"},
+	 "src": "0x0000000000000000000000000000000000030000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0x0", "delay": ["0x0", "0x1"]},
+	{"call": {"tag": "NoCall"},
+	 "src": "0x0000000000000000000000000000000000030000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+	 "gas": 12500000, "gasprice": "0x0", "value": "0x0", "delay": ["0xa", "0x3"]}
+]`
+
+func TestExportTests_RoundTrip(t *testing.T) {
+	calls, err := parser.ParseData([]byte(exportReproducer))
+	require.NoError(t, err)
+	expected, err := encoder.MarshalEchidna(calls)
+	require.NoError(t, err)
+
+	for _, template := range []string{"basic", "enigmadark"} {
+		t.Run(template, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Render(&out, GenerateConfig{
+				ContractName: "Replay",
+				Template:     template,
+				ReplayGroups: []types.ReplayGroup{
+					{TestName: "test_replay", Calls: calls, Property: "echidna_solvency", Origin: "reproducers"},
+					{TestName: "test_other", Calls: calls[:2]},
+					{TestName: "test_custom", Calls: calls[2:3]},
+				},
+			})
+			require.NoError(t, err)
+
+			// Only transactions that differ from Echidna's defaults are recorded
+			assert.NotContains(t, out.String(), "// Transactions: target 0x00a329c0648769A73afAc7F9381E08FB43dBEA72")
+			assert.Contains(t, out.String(), "// Transactions: target 0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496, gas 1000000, gas price 1000000000")
+			assert.Contains(t, out.String(), `"say \"hi\" \\o/"`)
+			assert.Contains(t, out.String(), `string(hex"6c696e650a627265616b2c20636166c3a9")`)
+			assert.Contains(t, out.String(), "// Transaction: target 0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496, gas 1000000, gas price 1000000000")
+
+			tests, err := ExportTests(out.String(), []string{"test_replay"}, ExportOptions{})
+			require.NoError(t, err)
+			require.Len(t, tests, 1)
+			assert.Equal(t, "test_replay", tests[0].Name)
+
+			data, err := encoder.MarshalEchidna(tests[0].Calls)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(data))
+
+			// The target and gas options only apply to tests that do not record them
+			options := ExportOptions{Target: "0x0000000000000000000000000000000000000001", Gas: 1}
+			others, err := ExportTests(out.String(), []string{"test_other", "test_custom"}, options)
+			require.NoError(t, err)
+			require.Len(t, others, 2)
+			assert.Equal(t, options.Target, others[0].Calls[0].Dst)
+			assert.Equal(t, options.Gas, others[0].Calls[0].Gas)
+			assert.Equal(t, calls[2].Dst, others[1].Calls[0].Dst)
+			assert.Equal(t, calls[2].Gas, others[1].Calls[0].Gas)
+
+			// The exported file is a valid reproducer itself
+			var reproducer types.EchidnaReproducer
+			require.NoError(t, json.Unmarshal(data, &reproducer))
+			assert.Len(t, reproducer, len(calls))
+		})
+	}
+}
+
+func TestExportTests_EditedTest(t *testing.T) {
+	previous := logger.Output
+	logger.Output = io.Discard
+	t.Cleanup(func() { logger.Output = previous })
+
+	source := `contract Replay is Test {
+    address constant ALICE = 0x000000000000000000000000000000000000a11c;
+
+    function test_edited() public {
+        _setUpActor(ALICE);
+        /* hand-edited variant */
+        Order[] memory arr0 = new Order[](1);
+        arr0[0] = Order(address(0x000000000000000000000000000000000000dEaD), uint128(3));
+        Tester.fill(arr0, ALICE);
+        _setUpActor(USER2);
+        _delayBlocks(4);
+        _delayBlocks(5);
+        console.log("debug");
+    }
+
+    function test_deploy() public {
+        _setUpActor(USER1);
+        Vault created0 = new Vault(1);
+    }
+
+    function helper() internal {}
+}`
+
+	_, err := ExportTests(source, nil, ExportOptions{Target: "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496", Gas: 1000000})
+	assert.ErrorContains(t, err, "failed to export test_deploy")
+	assert.ErrorContains(t, err, "creation code of Vault is unknown")
+
+	tests, err := ExportTests(source, []string{"test_edited"}, ExportOptions{Target: "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496", Gas: 1000000})
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+
+	calls := tests[0].Calls
+	require.Len(t, calls, 3)
+	assert.Equal(t, "fill", calls[0].FunctionName)
+	assert.Equal(t, "0x000000000000000000000000000000000000a11c", calls[0].Src)
+	assert.Equal(t, "0x7FA9385bE102ac3EAc297483Dd6233D62b3e1496", calls[0].Dst)
+	assert.Equal(t, int64(1000000), calls[0].Gas)
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "(address,uint128)[]", Elements: []types.ParsedParam{
+			{Type: "(address,uint128)", Elements: []types.ParsedParam{
+				{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+				{Type: "uint128", Value: "3"},
+			}},
+		}},
+		{Type: "address", Value: "0x000000000000000000000000000000000000a11c"},
+	}, calls[0].Parameters)
+
+	// Consecutive block delays are separate pure delays
+	for i, blocks := range []string{"4", "5"} {
+		call := calls[i+1]
+		assert.Empty(t, call.FunctionName)
+		assert.Equal(t, "0x0000000000000000000000000000000000020000", call.Src)
+		assert.True(t, call.HasBlockDelay)
+		assert.Equal(t, blocks, call.BlockDelayValue)
+		assert.False(t, call.HasDelay)
+	}
+
+	_, err = ExportTests(source, []string{"test_missing"}, ExportOptions{})
+	assert.ErrorContains(t, err, "test function test_missing not found")
+
+	_, err = ExportTests(strings.Replace(source, "ALICE", "BOB", 1), []string{"test_edited"}, ExportOptions{})
+	assert.ErrorContains(t, err, "unknown actor ALICE")
+}
@@ -1,13 +1,20 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
 	"github.com/Enigma-Dark/runes/internal/templates"
 	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
 )
 
 const DefaultTemplate = "enigmadark"
@@ -17,19 +24,42 @@ type GenerateConfig struct {
 	ContractName string
 	OutputFile   string
 	ReplayGroups []types.ReplayGroup
-	Template     string // Template name to use
+	Template     string           // Template name to use
+	Actors       *ActorTable      // Sender to actor constant mapping (defaults to Echidna's senders)
+	ABI          *abi.ABI         // Target contract ABI used to resolve overloads and struct names (optional)
+	Artifacts    *abi.ArtifactSet // Artifacts of deployed contracts, used for constructor struct names and imports (optional)
+}
+
+// GroupError is a replay group that could not be converted, naming the reproducer file it
+// was parsed from
+type GroupError struct {
+	FileName string
+	Err      error
+}
+
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("failed to convert %s: %v", e.FileName, e.Err)
+}
+
+func (e *GroupError) Unwrap() error {
+	return e.Err
 }
 
 // templateData holds data for the template
 type templateData struct {
 	ContractName string
 	ReplayGroups []templateReplayGroup
+	Actors       []Actor
+	Imports      []string // Import directives for contracts deployed by the replays
 }
 
 // templateReplayGroup represents a replay group with template-formatted calls
 type templateReplayGroup struct {
 	TestName      string
 	TemplateCalls []templateCall
+	Check         string // Statement checking the broken property after the calls, if any
+	Origin        string // Corpus folder the sequence comes from, if any
+	Transactions  string // Target, gas and gas price of the transactions when they are not Echidna's defaults, recorded in a comment
 }
 
 // templateCall represents a call in the template
@@ -38,6 +68,31 @@ type templateCall struct {
 	IsFunctionCall bool
 	FunctionName   string
 	ParamList      string
+	Statements     []string // Statements to emit before the call (e.g. memory array construction)
+	HasValue       bool     // Whether the call sends ETH (payable functions)
+	Value          string   // The ETH value in wei
+	Actor          string   // The actor constant making the call
+	Comment        string   // Comment emitted above the call, e.g. for the call breaking an assertion
+
+	// Raw call fields (HasValue, Value and Actor are shared with function calls)
+	IsRawCall bool
+	Calldata  string // Calldata as hex digits, without the 0x prefix
+
+	// Deployment fields (ParamList, Statements, HasValue, Value and Calldata are shared)
+	IsCreate     bool
+	ContractName string // Deployed contract, empty when the creation code matches no artifact
+	CreatedName  string // Variable holding the deployed contract or its address
+
+	// Skipped transaction fields
+	IsSkipped bool
+	Note      string
+
+	// Pure delay fields, marking delays that are not followed by a call (Echidna's NoCall)
+	IsPureDelay bool
+
+	// Target, gas and gas price of a transaction that differ from those of the test,
+	// recorded in a comment so exported tests keep them
+	Transaction string
 
 	// Actor setup fields
 	IsSetUpActor bool
@@ -46,6 +101,10 @@ type templateCall struct {
 	// Delay fields
 	IsDelay    bool
 	DelayValue string
+
+	// Block delay fields
+	IsBlockDelay    bool
+	BlockDelayValue string
 }
 
 // GenerateFoundryTest generates a Foundry test file from replay groups
@@ -54,86 +113,163 @@ func GenerateFoundryTest(config GenerateConfig) error {
 		return fmt.Errorf("no replay groups to generate")
 	}
 
+	// Render before touching the output so a failure leaves an existing file intact
+	var buf bytes.Buffer
+	if err := Render(&buf, config); err != nil {
+		return err
+	}
+
 	// Create output directory if it doesn't exist
 	outputDir := filepath.Dir(config.OutputFile)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Initialize template manager
-	templateManager := templates.NewManager()
-	if err := templateManager.LoadBuiltinTemplates(); err != nil {
-		return fmt.Errorf("failed to load builtin templates: %w", err)
+	if err := os.WriteFile(config.OutputFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
 
-	// Determine which template to use
-	templateName := config.Template
-	if templateName == "" {
-		templateName = DefaultTemplate
-	}
+	return nil
+}
 
-	// Try to load external template if it looks like a file path
-	if strings.Contains(templateName, "/") || strings.Contains(templateName, "\\") || strings.HasSuffix(templateName, ".tmpl") {
-		baseName := strings.TrimSuffix(filepath.Base(templateName), ".tmpl")
-		if err := templateManager.LoadExternalTemplate(baseName, templateName); err != nil {
-			return fmt.Errorf("failed to load external template: %w", err)
-		}
-		templateName = baseName
+// Render writes the Foundry test generated from the replay groups to w.
+// config.OutputFile is not used.
+func Render(w io.Writer, config GenerateConfig) error {
+	if len(config.ReplayGroups) == 0 {
+		return fmt.Errorf("no replay groups to generate")
 	}
 
-	// Get the template
-	tmpl, err := templateManager.GetTemplate(templateName)
+	tmpl, err := loadTemplate(config.Template)
 	if err != nil {
 		return err
 	}
 
+	actors := config.Actors
+	if actors == nil {
+		if actors, err = NewActorTable(nil); err != nil {
+			return err
+		}
+	}
+
 	// Prepare template data
 	data := templateData{
 		ContractName: config.ContractName,
 	}
 
 	// Convert replay groups to template format
+	var unnamedStructs []string
 	for _, group := range config.ReplayGroups {
+		transactions, err := groupTransactions(group.Calls)
+		if err != nil {
+			return &GroupError{FileName: group.FileName, Err: err}
+		}
+
+		var renderer paramRenderer
+		templateCalls, err := convertToTemplateCalls(group.Calls, transactions, actors, config.ABI, config.Artifacts, &renderer)
+		if err != nil {
+			return &GroupError{FileName: group.FileName, Err: err}
+		}
+		if renderer.unnamedStructs > 0 {
+			unnamedStructs = append(unnamedStructs, group.TestName)
+		}
+
+		check, err := applyProperty(group.Property, templateCalls, config.ABI)
+		if err != nil {
+			return &GroupError{FileName: group.FileName, Err: err}
+		}
+
 		templateGroup := templateReplayGroup{
 			TestName:      group.TestName,
-			TemplateCalls: convertToTemplateCalls(group.Calls),
+			TemplateCalls: templateCalls,
+			Check:         check,
+			Origin:        group.Origin,
+		}
+		// Export assumes the defaults for tests that record nothing
+		if !strings.EqualFold(transactions, defaultTransactions) {
+			templateGroup.Transactions = transactions
 		}
 		data.ReplayGroups = append(data.ReplayGroups, templateGroup)
 	}
 
-	// Create output file
-	file, err := os.Create(config.OutputFile)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+	if len(unnamedStructs) > 0 {
+		logger.Warn(fmt.Sprintf("tuple arguments of %s are rendered as %s(...), which does not compile: pass --abi or --artifacts to name their structs",
+			strings.Join(unnamedStructs, ", "), structPlaceholder), "tests", unnamedStructs)
 	}
-	defer file.Close()
+
+	// Collect actors after conversion so constants generated for unmapped senders are included
+	data.Actors = actors.Actors()
+	data.Imports = deployedImports(config.ReplayGroups, config.Artifacts)
 
 	// Execute template
-	if err := tmpl.Execute(file, data); err != nil {
+	if err := tmpl.Execute(w, data); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
 	return nil
 }
 
+// loadTemplate resolves a builtin or registered template name, or the path of a custom .tmpl file
+func loadTemplate(templateName string) (*template.Template, error) {
+	templateManager := templates.NewManager()
+	if err := templateManager.LoadBuiltinTemplates(); err != nil {
+		return nil, fmt.Errorf("failed to load builtin templates: %w", err)
+	}
+	templateManager.LoadRegisteredTemplates()
+
+	// Determine which template to use
+	if templateName == "" {
+		templateName = DefaultTemplate
+	}
+
+	// Try to load external template if it looks like a file path
+	if strings.Contains(templateName, "/") || strings.Contains(templateName, "\\") || strings.HasSuffix(templateName, ".tmpl") {
+		baseName := strings.TrimSuffix(filepath.Base(templateName), ".tmpl")
+		if err := templateManager.LoadExternalTemplate(baseName, templateName); err != nil {
+			return nil, fmt.Errorf("failed to load external template: %w", err)
+		}
+		templateName = baseName
+	}
+
+	return templateManager.GetTemplate(templateName)
+}
+
 // ListAvailableTemplates returns a list of available template names
 func ListAvailableTemplates() ([]string, error) {
 	templateManager := templates.NewManager()
 	if err := templateManager.LoadBuiltinTemplates(); err != nil {
 		return nil, fmt.Errorf("failed to load builtin templates: %w", err)
 	}
+	templateManager.LoadRegisteredTemplates()
 	return templateManager.ListTemplates(), nil
 }
 
-// convertToTemplateCalls converts ParsedCalls to templateCalls with proper sequencing
-func convertToTemplateCalls(calls []types.ParsedCall) []templateCall {
+// convertToTemplateCalls converts ParsedCalls to templateCalls with proper sequencing.
+// Transactions whose target, gas and gas price differ from those of the test, described
+// by transactions, carry their own. Arguments are rendered with renderer.
+func convertToTemplateCalls(calls []types.ParsedCall, transactions string, actors *ActorTable, contractABI *abi.ABI, artifacts *abi.ArtifactSet, renderer *paramRenderer) ([]templateCall, error) {
 	var result []templateCall
 	var lastActor string
+	var createCount int
 
 	for _, call := range calls {
-		// Check if we need to set up a new actor
-		currentActor := mapAddressToActor(call.Src)
+		transaction, err := transactionInfo(call)
+		if err != nil {
+			return nil, err
+		}
+		if transaction == transactions {
+			transaction = ""
+		}
+
+		// Check if we need to set up a new actor (pure delays may not carry a sender)
+		currentActor := lastActor
+		if call.Src != "" {
+			var err error
+			if currentActor, err = actors.Resolve(call.Src); err != nil {
+				return nil, err
+			}
+		}
 		if currentActor != lastActor {
+			logger.Debug("switched actor", "sender", call.Src, "actor", currentActor)
 			result = append(result, templateCall{
 				IsSetUpActor: true,
 				ActorAddress: currentActor,
@@ -149,35 +285,195 @@ func convertToTemplateCalls(calls []types.ParsedCall) []templateCall {
 			})
 		}
 
-		// Add the function call
-		if call.FunctionName != "" {
-			var paramValues []string
-			for _, param := range call.Parameters {
-				paramValues = append(paramValues, param.Value)
+		// Add block delay if specified
+		if call.HasBlockDelay {
+			result = append(result, templateCall{
+				IsBlockDelay:    true,
+				BlockDelayValue: call.BlockDelayValue,
+			})
+		}
+
+		switch call.Kind {
+		case types.CallKindRaw:
+			value, err := utils.ParseHexQuantity(call.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for raw call: %w", err)
+			}
+			result = append(result, templateCall{
+				IsRawCall:   true,
+				Calldata:    strings.TrimPrefix(call.Calldata, "0x"),
+				HasValue:    value.Sign() > 0,
+				Value:       value.String(),
+				Actor:       currentActor,
+				Transaction: transaction,
+			})
+			continue
+		case types.CallKindCreate:
+			created, err := convertCreate(call, artifacts, renderer)
+			if err != nil {
+				return nil, err
 			}
+			created.CreatedName = fmt.Sprintf("created%d", createCount)
+			created.Actor = currentActor
+			created.Transaction = transaction
+			createCount++
+			result = append(result, created)
+			continue
+		case types.CallKindUnsupported:
+			logger.Debug("left transaction as a comment", "sender", call.Src, "reason", call.Note)
+			result = append(result, templateCall{
+				IsSkipped: true,
+				Note:      call.Note,
+			})
+			continue
+		}
 
+		// Calls without a function name are pure delays
+		if call.FunctionName == "" {
 			result = append(result, templateCall{
-				IsFunctionCall: true,
-				FunctionName:   call.FunctionName,
-				ParamList:      strings.Join(paramValues, ", "),
+				IsPureDelay: true,
+				Transaction: transaction,
 			})
+			continue
+		}
+
+		// Add the function call
+		value, err := utils.ParseHexQuantity(call.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for call to %s: %w", call.FunctionName, err)
+		}
+
+		var args []abi.Argument
+		var overloaded bool
+		if contractABI != nil {
+			method, isOverloaded, err := contractABI.Resolve(call.FunctionName, parameterTypes(call.Parameters))
+			if err != nil {
+				return nil, err
+			}
+			if value.Sign() > 0 && !method.IsPayable() {
+				return nil, fmt.Errorf("reproducer sends %s wei to %s which is not payable", value, method.Signature())
+			}
+			args, overloaded = method.Inputs, isOverloaded
 		}
+
+		paramList, err := renderer.renderList(call.Parameters, args, overloaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render call to %s: %w", call.FunctionName, err)
+		}
+
+		result = append(result, templateCall{
+			IsFunctionCall: true,
+			FunctionName:   call.FunctionName,
+			ParamList:      paramList,
+			Statements:     renderer.takeStatements(),
+			HasValue:       value.Sign() > 0,
+			Value:          value.String(),
+			Actor:          currentActor,
+			Transaction:    transaction,
+		})
 	}
 
-	return result
+	return result, nil
+}
+
+// groupTransactions returns the target, gas and gas price of the first transaction of a
+// replay with a known target, which the test records for all its transactions
+func groupTransactions(calls []types.ParsedCall) (string, error) {
+	for _, call := range calls {
+		if call.Dst != "" && call.Kind != types.CallKindUnsupported {
+			return transactionInfo(call)
+		}
+	}
+	return "", nil
+}
+
+// defaultTransactions describes the transactions of Echidna, which ExportTests falls back to
+var defaultTransactions = fmt.Sprintf("target %s, gas %d, gas price 0", DefaultExportTarget, DefaultExportGas)
+
+// transactionInfo describes the target, gas and gas price of a transaction, which
+// ExportTests reads back. It is empty when the target is unknown.
+func transactionInfo(call types.ParsedCall) (string, error) {
+	if call.Dst == "" || call.Kind == types.CallKindUnsupported {
+		return "", nil
+	}
+	gasPrice, err := utils.ParseHexQuantity(call.GasPrice)
+	if err != nil {
+		return "", fmt.Errorf("invalid gas price: %w", err)
+	}
+	return fmt.Sprintf("target %s, gas %d, gas price %s", call.Dst, call.Gas, gasPrice), nil
+}
+
+// convertCreate converts a deployment to a template call. Known contracts are deployed
+// with new and their decoded constructor arguments, others from the raw creation code.
+func convertCreate(call types.ParsedCall, artifacts *abi.ArtifactSet, renderer *paramRenderer) (templateCall, error) {
+	value, err := utils.ParseHexQuantity(call.Value)
+	if err != nil {
+		return templateCall{}, fmt.Errorf("invalid value for deployment: %w", err)
+	}
+
+	created := templateCall{
+		IsCreate: true,
+		HasValue: value.Sign() > 0,
+		Value:    value.String(),
+	}
+	if call.ContractName == "" {
+		created.Calldata = strings.TrimPrefix(call.Calldata, "0x")
+		return created, nil
+	}
+
+	var args []abi.Argument
+	if artifacts != nil {
+		if artifact, err := artifacts.Find(call.ContractName); err == nil && artifact.ABI.Constructor != nil {
+			args = artifact.ABI.Constructor.Inputs
+		}
+	}
+	if len(args) != len(call.Parameters) {
+		args = nil
+	}
+
+	paramList, err := renderer.renderList(call.Parameters, args, false)
+	if err != nil {
+		return templateCall{}, fmt.Errorf("failed to render deployment of %s: %w", call.ContractName, err)
+	}
+
+	created.ContractName = call.ContractName
+	created.ParamList = paramList
+	created.Statements = renderer.takeStatements()
+	return created, nil
+}
+
+// deployedImports returns the import directives of the contracts deployed by the replays,
+// for the artifacts whose source file is known
+func deployedImports(groups []types.ReplayGroup, artifacts *abi.ArtifactSet) []string {
+	if artifacts == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var imports []string
+	for _, group := range groups {
+		for _, call := range group.Calls {
+			if call.Kind != types.CallKindCreate || call.ContractName == "" || seen[call.ContractName] {
+				continue
+			}
+			seen[call.ContractName] = true
+
+			artifact, err := artifacts.Find(call.ContractName)
+			if err != nil || artifact.Source == "" {
+				continue
+			}
+			imports = append(imports, fmt.Sprintf("import {%s} from %q;", artifact.Name, artifact.Source))
+		}
+	}
+	sort.Strings(imports)
+	return imports
 }
 
-// mapAddressToActor maps source addresses to actor constants
-func mapAddressToActor(address string) string {
-	switch address {
-	case "0x0000000000000000000000000000000000010000":
-		return "USER1"
-	case "0x0000000000000000000000000000000000020000":
-		return "USER2"
-	case "0x0000000000000000000000000000000000030000":
-		return "USER3"
-	default:
-		// For unknown addresses, map to USER1 as default
-		return "USER1"
+// parameterTypes returns the Solidity types of a parameter list
+func parameterTypes(params []types.ParsedParam) []string {
+	result := make([]string, 0, len(params))
+	for _, param := range params {
+		result = append(result, param.Type)
 	}
+	return result
 }
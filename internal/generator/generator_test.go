@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+func TestRender_RawCreateAndSkippedCalls(t *testing.T) {
+	calls := []types.ParsedCall{
+		{Kind: types.CallKindRaw, Calldata: "0xcafebabe", Src: "0x10000", Value: "0x0"},
+		{Kind: types.CallKindRaw, Calldata: "0x01", Src: "0x10000", Value: "0x64"},
+		{Kind: types.CallKindCreate, Calldata: "0x6080", Src: "0x10000", Value: "0x0"},
+		{Kind: types.CallKindCreate, ContractName: "Vault", Src: "0x10000", Value: "0x0",
+			Parameters: []types.ParsedParam{{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"}}},
+		{Kind: types.CallKindUnsupported, Note: "SolSelfdestruct transactions are not supported", Src: "0x10000"},
+	}
+
+	artifacts, err := abi.LoadArtifacts("../abi/testdata/out")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = Render(&out, GenerateConfig{
+		ContractName: "Replay",
+		Template:     "basic",
+		Artifacts:    artifacts,
+		ReplayGroups: []types.ReplayGroup{{TestName: "test_replay", Calls: calls}},
+	})
+	require.NoError(t, err)
+
+	generated := out.String()
+	assert.Contains(t, generated, `address(Tester).call(hex"cafebabe");`)
+	assert.Contains(t, generated, `address(Tester).call{value: 100}(hex"01");`)
+	assert.Contains(t, generated, `import {Vault} from "src/Vault.sol";`)
+	assert.Contains(t, generated, `bytes memory created0Code = hex"6080";`)
+	assert.Contains(t, generated, "created0 := create(0, add(created0Code, 0x20), mload(created0Code))")
+	assert.Contains(t, generated, "Vault created1 = new Vault(0x000000000000000000000000000000000000dEaD);")
+	assert.Contains(t, generated, "// Skipped transaction: SolSelfdestruct transactions are not supported")
+}
+
+func TestRender_PropertyChecks(t *testing.T) {
+	call := func(name string) types.ParsedCall {
+		return types.ParsedCall{FunctionName: name, Src: "0x10000", Value: "0x0"}
+	}
+	contractABI, err := abi.Parse([]byte(`[
+		{"type": "function", "name": "deposit", "inputs": [], "outputs": [], "stateMutability": "nonpayable"},
+		{"type": "function", "name": "check_balance", "inputs": [], "outputs": [], "stateMutability": "nonpayable"},
+		{"type": "function", "name": "invariant_solvency", "inputs": [], "outputs": [{"name": "", "type": "bool"}], "stateMutability": "view"}
+	]`))
+	require.NoError(t, err)
+
+	groups := []types.ReplayGroup{
+		{TestName: "test_property", Property: "echidna_solvency", Calls: []types.ParsedCall{call("deposit")}},
+		{TestName: "test_invariant", Property: "invariant_no_debt", Calls: []types.ParsedCall{call("deposit")}},
+		{TestName: "test_invariant_abi", Property: "invariant_solvency", Calls: []types.ParsedCall{call("deposit")}},
+		{TestName: "test_assertion", Property: "check_balance(uint256)", Calls: []types.ParsedCall{call("check_balance"), call("deposit")}},
+	}
+
+	for _, template := range []string{"basic", "enigmadark"} {
+		t.Run(template, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Render(&out, GenerateConfig{ContractName: "Replay", Template: template, ReplayGroups: groups, ABI: contractABI})
+			require.NoError(t, err)
+
+			generated := out.String()
+			assert.Contains(t, generated, `assertTrue(Tester.echidna_solvency(), "echidna_solvency");`)
+			assert.Contains(t, generated, "\n        Tester.invariant_no_debt();")
+			assert.Contains(t, generated, `assertTrue(Tester.invariant_solvency(), "invariant_solvency");`)
+			assert.Contains(t, generated, "// Breaks an assertion in check_balance(uint256)\n        Tester.check_balance();")
+		})
+	}
+
+	// An assertion failure must be replayed by the sequence
+	var out bytes.Buffer
+	err = Render(&out, GenerateConfig{ContractName: "Replay", ReplayGroups: []types.ReplayGroup{
+		{TestName: "test_assertion", Property: "withdraw(uint256)", Calls: []types.ParsedCall{call("deposit")}},
+	}})
+	assert.ErrorContains(t, err, "never calls withdraw")
+}
+
+func TestRender_UnnamedStructs(t *testing.T) {
+	var logs bytes.Buffer
+	previous := logger.Output
+	logger.Output = &logs
+	t.Cleanup(func() { logger.Output = previous })
+
+	tuple := types.ParsedParam{Type: "(uint256,bool)", Elements: []types.ParsedParam{
+		{Type: "uint256", Value: "1"},
+		{Type: "bool", Value: "true"},
+	}}
+	groups := []types.ReplayGroup{
+		{TestName: "test_scalar", Calls: []types.ParsedCall{{FunctionName: "f", Parameters: []types.ParsedParam{{Type: "uint256", Value: "1"}}, Src: "0x10000", Value: "0x0"}}},
+		{TestName: "test_tuple", Calls: []types.ParsedCall{{FunctionName: "f", Parameters: []types.ParsedParam{tuple}, Src: "0x10000", Value: "0x0"}}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, GenerateConfig{ContractName: "Replay", ReplayGroups: groups}))
+	assert.Contains(t, out.String(), "Tester.f(Tuple(uint256(1), true));")
+	assert.Contains(t, logs.String(), "pass --abi or --artifacts")
+	assert.Contains(t, logs.String(), "test_tuple")
+	assert.NotContains(t, logs.String(), "test_scalar")
+
+	// Struct names from the ABI need no warning
+	contractABI, err := abi.Parse([]byte(`[{"type": "function", "name": "f", "stateMutability": "nonpayable", "outputs": [],
+		"inputs": [{"name": "order", "type": "tuple", "internalType": "struct Tester.Order", "components": [{"name": "amount", "type": "uint256"}, {"name": "open", "type": "bool"}]}]}]`))
+	require.NoError(t, err)
+	logs.Reset()
+	out.Reset()
+	require.NoError(t, Render(&out, GenerateConfig{ContractName: "Replay", ReplayGroups: groups[1:], ABI: contractABI}))
+	assert.Contains(t, out.String(), "Tester.f(Tester.Order(uint256(1), true));")
+	assert.Empty(t, logs.String())
+}
+
+func TestRender_PayableCalls(t *testing.T) {
+	calls := []types.ParsedCall{
+		{FunctionName: "donate", Src: "0x10000", Value: "0xde0b6b3a7640000"},
+		{FunctionName: "deposit", Parameters: []types.ParsedParam{{Type: "uint256", Value: "5"}}, Src: "0x10000", Value: "0x0"},
+	}
+
+	for template, funder := range map[string]string{"basic": "USER1", "enigmadark": "address(this)"} {
+		t.Run(template, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Render(&out, GenerateConfig{
+				ContractName: "Replay",
+				Template:     template,
+				ReplayGroups: []types.ReplayGroup{{TestName: "test_replay", Calls: calls}},
+			})
+			require.NoError(t, err)
+
+			generated := out.String()
+			assert.Contains(t, generated, "vm.deal("+funder+", "+funder+".balance + 1000000000000000000);\n        Tester.donate{value: 1000000000000000000}();")
+			assert.Contains(t, generated, "Tester.deposit(5);")
+			assert.Equal(t, 1, strings.Count(generated, "vm.deal("))
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// replaySectionBanner marks the replay tests section of the enigmadark template
+const replaySectionBanner = "REPLAY TESTS"
+
+var (
+	functionHeader   = regexp.MustCompile(`^function\s+(\w+)\s*\(`)
+	importDirective  = regexp.MustCompile(`(?m)^import\s[^;]*;[ \t]*$`)
+	actorConstant    = regexp.MustCompile(`(?m)^[ \t]*address\s+constant\s+(\w+)\s*=\s*(0x[0-9a-fA-F]{1,40})\s*;[ \t]*$`)
+	bannerLine       = regexp.MustCompile(`(?m)^[ \t]*//\s*/{10,}[ \t]*$`)
+	whitespaceRegexp = regexp.MustCompile(`\s+`)
+)
+
+// MergeResult describes what MergeFoundryTest changed in an existing contract
+type MergeResult struct {
+	Added   []string          // Test functions inserted, with their final names
+	Updated []string          // Existing test functions whose body was replaced
+	Skipped []string          // Test functions whose call sequence was already present
+	Renamed map[string]string // Original name to final name of added functions that collided
+	Created bool              // Whether the output did not exist and was generated from scratch
+}
+
+// solFunction is a function found in Solidity source
+type solFunction struct {
+	name  string
+	start int // Offset of the start of the line declaring the function
+	end   int // Offset just past the closing brace of the body
+	body  string
+}
+
+// MergeFoundryTest adds the replay groups to an existing test contract instead of
+// overwriting it. Only test functions whose call sequence is not already present are
+// inserted, after the last test of the replay section; name collisions get the first
+// free _2, _3, ... suffix. Imports and actor constants the new tests need are added too.
+// When the output does not exist yet, the contract is generated as usual.
+func MergeFoundryTest(config GenerateConfig) (*MergeResult, error) {
+	return UpdateFoundryTest(config, nil)
+}
+
+// UpdateFoundryTest merges the replay groups into an existing test contract like
+// MergeFoundryTest, except that the existing test functions named in replace are
+// rewritten with the body generated for the group of the same name instead of being
+// kept, e.g. when the reproducer they were generated from has been shrunk.
+func UpdateFoundryTest(config GenerateConfig, replace []string) (*MergeResult, error) {
+	existing, err := os.ReadFile(config.OutputFile)
+	if os.IsNotExist(err) {
+		if err := GenerateFoundryTest(config); err != nil {
+			return nil, err
+		}
+		result := &MergeResult{Created: true, Renamed: map[string]string{}}
+		for _, group := range config.ReplayGroups {
+			result.Added = append(result.Added, group.TestName)
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", config.OutputFile, err)
+	}
+
+	// Senders the contract already declares keep their constants
+	if config.Actors == nil {
+		if config.Actors, err = NewActorTable(nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, match := range actorConstant.FindAllStringSubmatch(string(existing), -1) {
+		config.Actors.Declare(match[1], match[2])
+	}
+
+	var rendered bytes.Buffer
+	if err := Render(&rendered, config); err != nil {
+		return nil, err
+	}
+
+	merged, result, err := mergeContracts(string(existing), rendered.String(), config.ReplayGroups, replace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge into %s: %w", config.OutputFile, err)
+	}
+
+	if len(result.Added) > 0 || len(result.Updated) > 0 {
+		if err := os.WriteFile(config.OutputFile, []byte(merged), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", config.OutputFile, err)
+		}
+	}
+	return result, nil
+}
+
+// ContractFunctions returns the names of the functions declared in an existing test contract,
+// or nothing when the file does not exist
+func ContractFunctions(path string) ([]string, error) {
+	source, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	functions, _, err := scanContract(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the functions of %s: %w", path, err)
+	}
+	names := make([]string, len(functions))
+	for i, function := range functions {
+		names[i] = function.name
+	}
+	return names, nil
+}
+
+// mergeContracts inserts the replay tests of the rendered contract into the existing source,
+// after rewriting the existing tests named in replace
+func mergeContracts(existing, rendered string, groups []types.ReplayGroup, replace []string) (string, *MergeResult, error) {
+	existingFunctions, contractEnd, err := scanContract(existing)
+	if err != nil {
+		return "", nil, err
+	}
+	renderedFunctions, _, err := scanContract(rendered)
+	if err != nil {
+		return "", nil, fmt.Errorf("generated contract: %w", err)
+	}
+
+	// Only the generated replay tests are merged, not the template's setUp and helpers
+	wanted := make(map[string]bool)
+	for _, group := range groups {
+		wanted[group.TestName] = true
+	}
+
+	result := &MergeResult{Renamed: map[string]string{}}
+	replaced := make(map[string]bool)
+	if len(replace) > 0 {
+		existing, replaced = replaceFunctions(existing, existingFunctions, rendered, renderedFunctions, replace, wanted)
+		for _, function := range renderedFunctions {
+			if replaced[function.name] {
+				result.Updated = append(result.Updated, function.name)
+			}
+		}
+		if existingFunctions, contractEnd, err = scanContract(existing); err != nil {
+			return "", nil, err
+		}
+	}
+
+	names := make(map[string]bool)
+	bodies := make(map[string]bool)
+	for _, function := range existingFunctions {
+		names[function.name] = true
+		bodies[normalizeBody(function.body)] = true
+	}
+
+	var blocks []string
+	for _, function := range renderedFunctions {
+		if !wanted[function.name] || slices.Contains(replace, function.name) && names[function.name] {
+			continue
+		}
+
+		body := normalizeBody(function.body)
+		if bodies[body] {
+			result.Skipped = append(result.Skipped, function.name)
+			continue
+		}
+		bodies[body] = true
+
+		name := function.name
+		for n := 2; names[name]; n++ {
+			name = fmt.Sprintf("%s_%d", function.name, n)
+		}
+		names[name] = true
+		if name != function.name {
+			result.Renamed[function.name] = name
+		}
+
+		block := rendered[function.start:function.end]
+		block = strings.Replace(block, "function "+function.name+"(", "function "+name+"(", 1)
+		blocks = append(blocks, block)
+		result.Added = append(result.Added, name)
+	}
+
+	if len(blocks) == 0 {
+		if len(result.Updated) > 0 {
+			existing = addActorConstants(existing, rendered)
+			existing = addImports(existing, rendered)
+		}
+		return existing, result, nil
+	}
+
+	// Insert the new tests, then the declarations they need above them
+	var merged string
+	if at, afterFunction := insertionPoint(existing, existingFunctions, contractEnd); afterFunction {
+		merged = existing[:at] + "\n\n" + strings.Join(blocks, "\n\n") + existing[at:]
+	} else {
+		merged = existing[:at] + strings.Join(blocks, "\n\n") + "\n\n" + existing[at:]
+	}
+	merged = addActorConstants(merged, rendered)
+	merged = addImports(merged, rendered)
+	return merged, result, nil
+}
+
+// replaceFunctions rewrites the existing functions named in replace, and generated for one of
+// the wanted groups, with their rendered version. It returns the new source and the names of
+// the functions whose body changed.
+func replaceFunctions(existing string, existingFunctions []solFunction, rendered string, renderedFunctions []solFunction, replace []string, wanted map[string]bool) (string, map[string]bool) {
+	blocks := make(map[string]solFunction)
+	for _, function := range renderedFunctions {
+		if wanted[function.name] && slices.Contains(replace, function.name) {
+			blocks[function.name] = function
+		}
+	}
+
+	replaced := make(map[string]bool)
+	// Replace from the end so the offsets of earlier functions stay valid
+	for i := len(existingFunctions) - 1; i >= 0; i-- {
+		function := existingFunctions[i]
+		block, ok := blocks[function.name]
+		if !ok || normalizeBody(block.body) == normalizeBody(function.body) {
+			continue
+		}
+		existing = existing[:function.start] + rendered[block.start:block.end] + existing[function.end:]
+		replaced[function.name] = true
+	}
+	return existing, replaced
+}
+
+// insertionPoint returns where new tests go: after the last test function of the replay
+// section, or at the start of the last line of the section when it has no tests yet.
+// The replay section is delimited by the REPLAY TESTS banner and the next banner, or is
+// the whole contract.
+func insertionPoint(source string, functions []solFunction, contractEnd int) (int, bool) {
+	sectionStart, sectionEnd := 0, contractEnd
+	if banner := strings.Index(source, replaySectionBanner); banner >= 0 {
+		sectionStart = banner
+		// Skip the closing rule of the banner itself before looking for the next one
+		rules := bannerLine.FindAllStringIndex(source[banner:], -1)
+		if len(rules) >= 2 && banner+rules[1][0] < contractEnd {
+			sectionEnd = banner + rules[1][0]
+		}
+	}
+
+	at := -1
+	for _, function := range functions {
+		if function.start >= sectionStart && function.end <= sectionEnd && strings.HasPrefix(function.name, "test") {
+			at = function.end
+		}
+	}
+	if at >= 0 {
+		return at, true
+	}
+
+	// Insert on its own line before the end of the section
+	return strings.LastIndex(source[:sectionEnd], "\n") + 1, false
+}
+
+// addActorConstants copies the actor constants of the rendered contract that the source lacks.
+// They go after the last constant of the source, or at the top of the contract.
+func addActorConstants(source, rendered string) string {
+	declared := make(map[string]bool)
+	var last []int
+	for _, match := range actorConstant.FindAllStringSubmatchIndex(source, -1) {
+		declared[source[match[2]:match[3]]] = true
+		last = match
+	}
+
+	var missing []string
+	for _, match := range actorConstant.FindAllStringSubmatch(rendered, -1) {
+		if !declared[match[1]] {
+			declared[match[1]] = true
+			missing = append(missing, match[0])
+		}
+	}
+	if len(missing) == 0 {
+		return source
+	}
+
+	at := -1
+	if last != nil {
+		at = last[1]
+	} else if contract := strings.Index(source, "contract "); contract >= 0 {
+		if brace := strings.Index(source[contract:], "{"); brace >= 0 {
+			at = contract + brace + 1
+		}
+	}
+	if at < 0 {
+		return source
+	}
+	return source[:at] + "\n" + strings.Join(missing, "\n") + source[at:]
+}
+
+// addImports copies the import directives of the rendered contract that the source lacks,
+// after the last import of the source
+func addImports(source, rendered string) string {
+	present := make(map[string]bool)
+	matches := importDirective.FindAllStringIndex(source, -1)
+	for _, match := range matches {
+		present[normalizeBody(source[match[0]:match[1]])] = true
+	}
+
+	var missing []string
+	for _, directive := range importDirective.FindAllString(rendered, -1) {
+		if key := normalizeBody(directive); !present[key] {
+			present[key] = true
+			missing = append(missing, strings.TrimSpace(directive))
+		}
+	}
+	if len(missing) == 0 {
+		return source
+	}
+	sort.Strings(missing)
+
+	if len(matches) == 0 {
+		return strings.Join(missing, "\n") + "\n" + source
+	}
+	at := matches[len(matches)-1][1]
+	return source[:at] + "\n" + strings.Join(missing, "\n") + source[at:]
+}
+
+// normalizeBody collapses whitespace so formatting differences do not hide a duplicate
+func normalizeBody(body string) string {
+	return strings.TrimSpace(whitespaceRegexp.ReplaceAllString(body, " "))
+}
+
+// scanContract finds the functions declared directly in the contracts of a Solidity source
+// and returns them with the offset of the closing brace of the last contract.
+// Comments and string literals are skipped so braces inside them are not counted.
+func scanContract(source string) ([]solFunction, int, error) {
+	var functions []solFunction
+	depth := 0
+	contractEnd := -1
+	var current *solFunction
+
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case strings.HasPrefix(source[i:], "//"):
+			if end := strings.IndexByte(source[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(source)
+			}
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, 0, fmt.Errorf("unterminated comment")
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			for i++; i < len(source) && source[i] != c; i++ {
+				if source[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{':
+			depth++
+			if depth == 2 && current == nil {
+				// A body opened directly in a contract: keep it when it belongs to a function
+				if function := precedingFunction(source, i); function != nil {
+					current = function
+				}
+			}
+		case c == '}':
+			depth--
+			if depth < 0 {
+				return nil, 0, fmt.Errorf("unbalanced braces")
+			}
+			if depth == 1 && current != nil {
+				current.end = i + 1
+				current.body = source[strings.IndexByte(source[current.start:], '{')+current.start+1 : i]
+				functions = append(functions, *current)
+				current = nil
+			}
+			if depth == 0 {
+				contractEnd = i
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, 0, fmt.Errorf("unbalanced braces")
+	}
+	if contractEnd < 0 {
+		return nil, 0, fmt.Errorf("no contract found")
+	}
+	return functions, contractEnd, nil
+}
+
+// precedingFunction returns the function whose body opens at the brace at offset, if any
+func precedingFunction(source string, brace int) *solFunction {
+	// The header starts after the previous statement or block
+	headerStart := strings.LastIndexAny(source[:brace], ";{}") + 1
+	header := strings.TrimSpace(stripLineComments(source[headerStart:brace]))
+	match := functionHeader.FindStringSubmatch(header)
+	if match == nil {
+		return nil
+	}
+
+	declaration := strings.Index(source[headerStart:brace], "function")
+	if declaration < 0 {
+		return nil
+	}
+	declaration += headerStart
+	return &solFunction{
+		name:  match[1],
+		start: strings.LastIndex(source[:declaration], "\n") + 1,
+	}
+}
+
+// stripLineComments removes // comments, which may precede a function header
+func stripLineComments(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			lines[i] = line[:comment]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
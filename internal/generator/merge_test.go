@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+func TestMergeFoundryTest(t *testing.T) {
+	call := func(name, value, sender string) types.ParsedCall {
+		return types.ParsedCall{FunctionName: name, Parameters: []types.ParsedParam{{Type: "uint256", Value: value}}, Src: sender, Value: "0x0"}
+	}
+	deposit := types.ReplayGroup{TestName: "test_replay_deposit", Calls: []types.ParsedCall{call("deposit", "1", "0x10000")}}
+	otherDeposit := types.ReplayGroup{TestName: "test_replay_deposit", Calls: []types.ParsedCall{call("deposit", "2", "0x10000")}}
+	withdraw := types.ReplayGroup{TestName: "test_replay_withdraw", Calls: []types.ParsedCall{call("withdraw", "3", "0xabcdef")}}
+	sweep := types.ReplayGroup{TestName: "test_replay_sweep", Calls: []types.ParsedCall{call("sweep", "4", "0x123456")}}
+
+	for _, template := range []string{"basic", "enigmadark"} {
+		t.Run(template, func(t *testing.T) {
+			config := GenerateConfig{
+				ContractName: "Replay",
+				Template:     template,
+				OutputFile:   filepath.Join(t.TempDir(), "Replay.t.sol"),
+			}
+			merge := func(groups ...types.ReplayGroup) (*MergeResult, string) {
+				config.ReplayGroups = groups
+				config.Actors = nil
+				result, err := MergeFoundryTest(config)
+				require.NoError(t, err)
+				data, err := os.ReadFile(config.OutputFile)
+				require.NoError(t, err)
+				return result, string(data)
+			}
+
+			// A missing output is generated from scratch
+			result, existing := merge(deposit)
+			assert.True(t, result.Created)
+
+			// Manual edits survive the merge
+			existing = strings.Replace(existing, "function setUp() public {", "function setUp() public {\n        // edited by hand", 1)
+			require.NoError(t, os.WriteFile(config.OutputFile, []byte(existing), 0644))
+
+			result, merged := merge(deposit, otherDeposit, withdraw)
+			assert.Equal(t, []string{"test_replay_deposit"}, result.Skipped)
+			assert.Equal(t, []string{"test_replay_deposit_2", "test_replay_withdraw"}, result.Added)
+			assert.Equal(t, map[string]string{"test_replay_deposit": "test_replay_deposit_2"}, result.Renamed)
+
+			assert.Contains(t, merged, "// edited by hand")
+			assert.Equal(t, 1, strings.Count(merged, "function test_replay_deposit()"))
+			assert.Contains(t, merged, "Tester.deposit(2);")
+			assert.Contains(t, merged, "address constant USER4 = 0x0000000000000000000000000000000000abcDeF;")
+
+			// New tests follow the existing ones, before the helpers
+			assert.Less(t, strings.Index(merged, "function test_replay_deposit()"), strings.Index(merged, "function test_replay_withdraw()"))
+			assert.Less(t, strings.Index(merged, "function test_replay_withdraw()"), strings.Index(merged, "function _delay("))
+
+			// Constants declared by the contract are reused, new senders get the next free name
+			result, merged = merge(withdraw, sweep)
+			assert.Equal(t, []string{"test_replay_sweep"}, result.Added)
+			assert.Equal(t, 1, strings.Count(merged, "address constant USER4 "))
+			assert.Contains(t, merged, "address constant USER5 = 0x0000000000000000000000000000000000123456;")
+			assert.Contains(t, merged, "_setUpActor(USER5);")
+
+			// Merging again leaves the file untouched
+			result, again := merge(deposit, otherDeposit, withdraw, sweep)
+			assert.Empty(t, result.Added)
+			assert.Equal(t, merged, again)
+
+			// Replaced tests are rewritten in place, other tests and edits are kept
+			shrunk := types.ReplayGroup{TestName: "test_replay_withdraw", Calls: []types.ParsedCall{call("withdraw", "1", "0xabcdef")}}
+			config.ReplayGroups = []types.ReplayGroup{shrunk}
+			result, err := UpdateFoundryTest(config, []string{"test_replay_withdraw"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"test_replay_withdraw"}, result.Updated)
+			assert.Empty(t, result.Added)
+			data, err := os.ReadFile(config.OutputFile)
+			require.NoError(t, err)
+			updated := string(data)
+			assert.Equal(t, 1, strings.Count(updated, "function test_replay_withdraw()"))
+			assert.Contains(t, updated, "Tester.withdraw(1);")
+			assert.NotContains(t, updated, "Tester.withdraw(3);")
+			assert.Contains(t, updated, "Tester.sweep(4);")
+			assert.Contains(t, updated, "// edited by hand")
+		})
+	}
+}
+
+func TestScanContract(t *testing.T) {
+	source := `contract A {
+    // function fake() { "}" }
+    string constant S = "{";
+    function a() public {
+        if (true) { /* } */ }
+    }
+    modifier m() { _; }
+    function b() public { }
+}`
+	functions, end, err := scanContract(source)
+	require.NoError(t, err)
+	require.Len(t, functions, 2)
+	assert.Equal(t, "a", functions[0].name)
+	assert.Equal(t, "b", functions[1].name)
+	assert.Equal(t, len(source)-1, end)
+
+	_, _, err = scanContract("contract A { function a() public {")
+	assert.Error(t, err)
+}
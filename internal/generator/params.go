@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// structPlaceholder names tuple parameters whose struct name is unknown
+const structPlaceholder = "Tuple"
+
+// paramRenderer renders parsed parameters as typed Solidity literals.
+// Dynamic arrays cannot be written as literals, so they are built in memory
+// by statements that must be emitted before the call using them.
+type paramRenderer struct {
+	statements     []string
+	arrayCount     int
+	unnamedStructs int // Tuples named with structPlaceholder because the ABI is unknown
+}
+
+// renderList renders a parameter list as comma separated Solidity expressions.
+// args holds the matching ABI inputs when the target ABI is known, explicit forces
+// casts on every literal (needed to select between overloads).
+func (r *paramRenderer) renderList(params []types.ParsedParam, args []abi.Argument, explicit bool) (string, error) {
+	var values []string
+	for i, param := range params {
+		var arg *abi.Argument
+		if args != nil {
+			arg = &args[i]
+		}
+
+		value, err := r.render(param, arg, explicit)
+		if err != nil {
+			return "", fmt.Errorf("parameter %d: %w", i, err)
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// takeStatements returns the pending setup statements and resets them
+func (r *paramRenderer) takeStatements() []string {
+	statements := r.statements
+	r.statements = nil
+	return statements
+}
+
+// render renders a single parameter. Inside array literals every value is cast
+// explicitly, otherwise Solidity infers the smallest fitting type for the literal.
+func (r *paramRenderer) render(param types.ParsedParam, arg *abi.Argument, explicit bool) (string, error) {
+	switch {
+	case abi.IsArrayType(param.Type):
+		return r.renderArray(param, arg)
+	case abi.IsTupleType(param.Type):
+		return r.renderTuple(param, arg)
+	default:
+		return renderScalar(param, explicit)
+	}
+}
+
+// renderArray renders a fixed-size array as a literal and a dynamic array as a memory variable
+func (r *paramRenderer) renderArray(param types.ParsedParam, arg *abi.Argument) (string, error) {
+	var elementArg *abi.Argument
+	if arg != nil {
+		element := arg.Element()
+		elementArg = &element
+	}
+
+	var elements []string
+	for i, element := range param.Elements {
+		value, err := r.render(element, elementArg, true)
+		if err != nil {
+			return "", fmt.Errorf("element %d: %w", i, err)
+		}
+		elements = append(elements, value)
+	}
+
+	if !strings.HasSuffix(param.Type, "[]") {
+		return "[" + strings.Join(elements, ", ") + "]", nil
+	}
+
+	name := fmt.Sprintf("arr%d", r.arrayCount)
+	r.arrayCount++
+
+	declType := declarationType(param.Type, arg)
+	if strings.HasPrefix(declType, structPlaceholder+"[") {
+		r.unnamedStructs++
+	}
+	r.statements = append(r.statements, fmt.Sprintf("%s memory %s = new %s(%d);", declType, name, declType, len(elements)))
+	for i, element := range elements {
+		r.statements = append(r.statements, fmt.Sprintf("%s[%d] = %s;", name, i, element))
+	}
+	return name, nil
+}
+
+// renderTuple renders a tuple as a struct constructor call
+func (r *paramRenderer) renderTuple(param types.ParsedParam, arg *abi.Argument) (string, error) {
+	var components []string
+	for i, component := range param.Elements {
+		var componentArg *abi.Argument
+		if arg != nil && i < len(arg.Components) {
+			componentArg = &arg.Components[i]
+		}
+
+		value, err := r.render(component, componentArg, true)
+		if err != nil {
+			return "", fmt.Errorf("component %d: %w", i, err)
+		}
+		components = append(components, value)
+	}
+	name := structName(arg)
+	if name == structPlaceholder {
+		r.unnamedStructs++
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(components, ", ")), nil
+}
+
+// renderScalar renders an elementary value as a Solidity literal with the casts its type needs
+func renderScalar(param types.ParsedParam, explicit bool) (string, error) {
+	switch {
+	case param.Type == "uint256":
+		if explicit {
+			return fmt.Sprintf("uint256(%s)", param.Value), nil
+		}
+		return param.Value, nil
+	case isNumericType(param.Type):
+		// int256 is cast as well so signed values are never mistaken for uint256
+		return fmt.Sprintf("%s(%s)", param.Type, param.Value), nil
+	case param.Type == "address":
+		checksummed, err := utils.ChecksumAddress(param.Value)
+		if err != nil {
+			return "", err
+		}
+		if explicit {
+			return fmt.Sprintf("address(%s)", checksummed), nil
+		}
+		return checksummed, nil
+	case param.Type == "bytes":
+		digits, err := bytesToHex(param.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`hex"%s"`, digits), nil
+	case strings.HasPrefix(param.Type, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(param.Type, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return "", fmt.Errorf("invalid fixed bytes type: %s", param.Type)
+		}
+		digits, err := bytesToHex(param.Value)
+		if err != nil {
+			return "", err
+		}
+		if len(digits) > size*2 {
+			return "", fmt.Errorf("value %s does not fit in %s", param.Value, param.Type)
+		}
+		// Fixed bytes are left-aligned, so shorter values are padded on the right
+		return fmt.Sprintf("%s(0x%s)", param.Type, digits+strings.Repeat("0", size*2-len(digits))), nil
+	case param.Type == "string":
+		literal := stringLiteral(param.Value)
+		if explicit && strings.HasPrefix(literal, `"`) {
+			return fmt.Sprintf("string(%s)", literal), nil
+		}
+		return literal, nil
+	default:
+		return param.Value, nil
+	}
+}
+
+// stringLiteral renders a string value, which the parsers wrap in quotes, as a Solidity
+// literal. Quotes and backslashes are escaped; strings that are not printable ASCII, which
+// plain string literals cannot hold, are written as string(hex"...").
+func stringLiteral(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			return fmt.Sprintf(`string(hex"%s")`, hex.EncodeToString([]byte(value)))
+		}
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// bytesToHex returns the hex digits of a bytes value, which may be 0x-prefixed hex or raw bytes
+func bytesToHex(value string) (string, error) {
+	if digits, ok := strings.CutPrefix(value, "0x"); ok {
+		if _, err := hex.DecodeString(digits); err != nil {
+			return "", fmt.Errorf("invalid hex bytes value: %q", value)
+		}
+		return strings.ToLower(digits), nil
+	}
+	return hex.EncodeToString([]byte(value)), nil
+}
+
+// declarationType converts an ABI type to a Solidity declaration type, naming tuples after their struct
+func declarationType(solType string, arg *abi.Argument) string {
+	if abi.IsArrayType(solType) {
+		open := strings.LastIndex(solType, "[")
+		var elementArg *abi.Argument
+		if arg != nil {
+			element := arg.Element()
+			elementArg = &element
+		}
+		return declarationType(solType[:open], elementArg) + solType[open:]
+	}
+	if abi.IsTupleType(solType) {
+		return structName(arg)
+	}
+	return solType
+}
+
+// structName returns the struct name of a tuple argument, or a placeholder when the ABI is unknown
+func structName(arg *abi.Argument) string {
+	if arg != nil {
+		if name := arg.StructName(); name != "" {
+			return name
+		}
+	}
+	return structPlaceholder
+}
+
+// isNumericType reports whether a Solidity type is a signed or unsigned integer
+func isNumericType(solType string) bool {
+	return strings.HasPrefix(solType, "uint") || strings.HasPrefix(solType, "int")
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+func TestRenderScalar(t *testing.T) {
+	tests := []struct {
+		param    types.ParsedParam
+		explicit bool
+		expected string
+	}{
+		{types.ParsedParam{Type: "uint256", Value: "1000"}, false, "1000"},
+		{types.ParsedParam{Type: "uint256", Value: "1000"}, true, "uint256(1000)"},
+		{types.ParsedParam{Type: "uint8", Value: "3"}, false, "uint8(3)"},
+		{types.ParsedParam{Type: "int256", Value: "-5"}, false, "int256(-5)"},
+		{types.ParsedParam{Type: "address", Value: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}, false, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{types.ParsedParam{Type: "bytes", Value: "0xDEADbeef"}, false, `hex"deadbeef"`},
+		{types.ParsedParam{Type: "bytes4", Value: "0x1234"}, false, "bytes4(0x12340000)"},
+		{types.ParsedParam{Type: "bool", Value: "true"}, true, "true"},
+		{types.ParsedParam{Type: "string", Value: `"hello"`}, false, `"hello"`},
+		{types.ParsedParam{Type: "string", Value: `"hello"`}, true, `string("hello")`},
+		{types.ParsedParam{Type: "string", Value: `"say "hi" \o/"`}, false, `"say \"hi\" \\o/"`},
+		{types.ParsedParam{Type: "string", Value: "\"a\nb\""}, false, `string(hex"610a62")`},
+		{types.ParsedParam{Type: "string", Value: `"é"`}, true, `string(hex"c3a9")`},
+	}
+
+	for _, tt := range tests {
+		rendered, err := renderScalar(tt.param, tt.explicit)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, rendered)
+	}
+
+	_, err := renderScalar(types.ParsedParam{Type: "bytes2", Value: "0x123456"}, false)
+	assert.Error(t, err)
+}
+
+func TestRenderList_StructArray(t *testing.T) {
+	args := []abi.Argument{{
+		Type:         "tuple[]",
+		InternalType: "struct Vault.Config[]",
+		Components:   []abi.Argument{{Type: "uint256"}, {Type: "address"}},
+	}}
+	params := []types.ParsedParam{{
+		Type: "(uint256,address)[]",
+		Elements: []types.ParsedParam{{
+			Type: "(uint256,address)",
+			Elements: []types.ParsedParam{
+				{Type: "uint256", Value: "7"},
+				{Type: "address", Value: "0x0000000000000000000000000000000000010000"},
+			},
+		}},
+	}}
+
+	var renderer paramRenderer
+	rendered, err := renderer.renderList(params, args, false)
+	require.NoError(t, err)
+	assert.Equal(t, "arr0", rendered)
+	assert.Equal(t, []string{
+		"Vault.Config[] memory arr0 = new Vault.Config[](1);",
+		"arr0[0] = Vault.Config(uint256(7), address(0x0000000000000000000000000000000000010000));",
+	}, renderer.takeStatements())
+}
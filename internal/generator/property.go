@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+// applyProperty makes a replay check the property it breaks. A property function is called
+// after the sequence: properties returning a bool are asserted, others (Foundry invariants)
+// are expected to revert on failure. An assertion failure, reported with the signature of
+// the failing function, is instead marked on the last call to that function.
+// It returns the check statement, empty for assertion failures and unknown properties.
+func applyProperty(property string, calls []templateCall, contractABI *abi.ABI) (string, error) {
+	if property == "" {
+		return "", nil
+	}
+
+	name, isSignature := property, false
+	if paren := strings.IndexByte(property, '('); paren >= 0 && strings.HasSuffix(property, ")") {
+		name, isSignature = property[:paren], true
+	}
+	if isSignature || lastFunctionCall(calls) == name {
+		for i := len(calls) - 1; i >= 0; i-- {
+			if calls[i].IsFunctionCall && calls[i].FunctionName == name {
+				calls[i].Comment = fmt.Sprintf("Breaks an assertion in %s", property)
+				logger.Debug("marked assertion failure on the last call", "property", property)
+				return "", nil
+			}
+		}
+		if isSignature {
+			return "", fmt.Errorf("assertion failure in %s but the replay never calls %s", property, name)
+		}
+	}
+
+	if !isIdentifier(name) {
+		return "", fmt.Errorf("invalid property name %q", property)
+	}
+
+	if returnsBool(name, contractABI) {
+		logger.Debug("asserting property", "property", name)
+		return fmt.Sprintf("assertTrue(Tester.%s(), %q);", name, name), nil
+	}
+	logger.Debug("calling property expected to revert on failure", "property", name)
+	return fmt.Sprintf("Tester.%s();", name), nil
+}
+
+// returnsBool reports whether a property function returns a bool. Without the ABI, this is
+// assumed for all properties but Foundry invariants (invariant_*), which return nothing.
+func returnsBool(name string, contractABI *abi.ABI) bool {
+	if contractABI != nil {
+		for _, method := range contractABI.MethodsNamed(name) {
+			if len(method.Inputs) == 0 {
+				return len(method.Outputs) == 1 && method.Outputs[0].Type == "bool"
+			}
+		}
+	}
+	return !strings.HasPrefix(name, "invariant")
+}
+
+// lastFunctionCall returns the name of the last function called by a replay
+func lastFunctionCall(calls []templateCall) string {
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i].IsFunctionCall {
+			return calls[i].FunctionName
+		}
+	}
+	return ""
+}
+
+// isIdentifier reports whether name is a valid Solidity identifier
+func isIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Log formats
+const (
+	FormatText = "text" // Human-readable progress output
+	FormatJSON = "json" // One JSON log record per line
+)
+
+// Output receives progress messages. It is stdout by default and is switched to
+// stderr when generated code is written to stdout.
+var Output io.Writer = os.Stdout
+
+var (
+	level  = new(slog.LevelVar)
+	format = FormatText
+	active = slog.New(&textHandler{})
+)
+
+// Configure sets the minimum level of the messages written and their format.
+// Debug shows per-transaction decisions, Warn (quiet) only problems.
+func Configure(minimum slog.Level, logFormat string) error {
+	switch logFormat {
+	case FormatText:
+		active = slog.New(&textHandler{})
+	case FormatJSON:
+		active = slog.New(slog.NewJSONHandler(outputWriter{}, &slog.HandlerOptions{Level: level}))
+	default:
+		return fmt.Errorf("unknown log format %q (expected 'text' or 'json')", logFormat)
+	}
+	level.Set(minimum)
+	format = logFormat
+	return nil
+}
+
+// AvoidStdout moves messages written to stdout to stderr, so stdout only carries
+// generated output. It returns a function restoring the previous Output.
+func AvoidStdout() func() {
+	previous := Output
+	if Output == os.Stdout {
+		Output = os.Stderr
+	}
+	return func() { Output = previous }
+}
+
+// Printf writes a formatted progress message to Output.
+// Progress text is only written at info level in text format.
+func Printf(format string, args ...interface{}) {
+	if showText() {
+		fmt.Fprintf(Output, format, args...)
+	}
+}
+
+// Println writes a progress message followed by a newline to Output
+func Println(args ...interface{}) {
+	if showText() {
+		fmt.Fprintln(Output, args...)
+	}
+}
+
+// Event reports a processing event at a level: text format shows the human-readable
+// line, JSON logs get a record with msg and attrs
+func Event(l slog.Level, line, msg string, attrs ...any) {
+	if format == FormatJSON {
+		active.Log(context.Background(), l, msg, attrs...)
+		return
+	}
+	if l >= level.Level() {
+		fmt.Fprintln(Output, line)
+	}
+}
+
+// Debug logs a decision that explains the output, e.g. how a transaction was decoded
+func Debug(msg string, attrs ...any) {
+	active.Debug(msg, attrs...)
+}
+
+// Info logs a progress message
+func Info(msg string, attrs ...any) {
+	active.Info(msg, attrs...)
+}
+
+// Warn logs a problem that does not stop processing
+func Warn(msg string, attrs ...any) {
+	active.Warn(msg, attrs...)
+}
+
+// Error logs a problem that stops the processing of an input
+func Error(msg string, attrs ...any) {
+	active.Error(msg, attrs...)
+}
+
+// showText reports whether human-readable progress text is written
+func showText() bool {
+	return format == FormatText && level.Level() <= slog.LevelInfo
+}
+
+// outputWriter writes to the current Output, which commands may switch while running
+type outputWriter struct{}
+
+func (outputWriter) Write(p []byte) (int, error) {
+	return Output.Write(p)
+}
+
+// textHandler writes log records as plain lines: warnings and errors are prefixed, and
+// debug records list their attributes
+type textHandler struct {
+	attrs []slog.Attr
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		line.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		line.WriteString("Warning: ")
+	case record.Level < slog.LevelInfo:
+		line.WriteString("debug: ")
+	}
+	line.WriteString(record.Message)
+
+	if record.Level < slog.LevelInfo {
+		writeAttr := func(attr slog.Attr) bool {
+			value := attr.Value.Resolve().String()
+			if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+				value = strconv.Quote(value)
+			}
+			fmt.Fprintf(&line, " %s=%s", attr.Key, value)
+			return true
+		}
+		for _, attr := range h.attrs {
+			writeAttr(attr)
+		}
+		record.Attrs(writeAttr)
+	}
+
+	line.WriteByte('\n')
+	_, err := io.WriteString(Output, line.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &textHandler{attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+}
+
+func (h *textHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureOutput configures the logger and collects what it writes
+func captureOutput(t *testing.T, minimum slog.Level, logFormat string) *bytes.Buffer {
+	previous := Output
+	var out bytes.Buffer
+	Output = &out
+	require.NoError(t, Configure(minimum, logFormat))
+	t.Cleanup(func() {
+		Output = previous
+		require.NoError(t, Configure(slog.LevelInfo, FormatText))
+	})
+	return &out
+}
+
+func TestConfigure_Text(t *testing.T) {
+	out := captureOutput(t, slog.LevelInfo, FormatText)
+
+	Printf("Processing %d files\n", 2)
+	Debug("decoded raw call", "selector", "0x12345678")
+	Warn("sender is not mapped", "sender", "0x10000")
+	Event(slog.LevelWarn, "  ✗ a.txt - no calls", "file failed", "file", "a.txt")
+
+	assert.Equal(t, "Processing 2 files\nWarning: sender is not mapped\n  ✗ a.txt - no calls\n", out.String())
+}
+
+func TestConfigure_Quiet(t *testing.T) {
+	out := captureOutput(t, slog.LevelWarn, FormatText)
+
+	Printf("Processing %d files\n", 2)
+	Info("Decoded 1 raw calls")
+	Event(slog.LevelInfo, "  ✓ a.txt (2 calls)", "file processed")
+	Event(slog.LevelWarn, "  ✗ b.txt - no calls", "file failed")
+	Error("failed to generate test file")
+
+	assert.Equal(t, "  ✗ b.txt - no calls\nError: failed to generate test file\n", out.String())
+}
+
+func TestConfigure_Verbose(t *testing.T) {
+	out := captureOutput(t, slog.LevelDebug, FormatText)
+
+	Debug("switched actor", "sender", "0x10000", "actor", "USER1", "reason", "not mapped")
+	Info("Decoded 1 raw calls", "decoded", 1)
+
+	assert.Equal(t, "debug: switched actor sender=0x10000 actor=USER1 reason=\"not mapped\"\nDecoded 1 raw calls\n", out.String())
+}
+
+func TestConfigure_JSON(t *testing.T) {
+	out := captureOutput(t, slog.LevelInfo, FormatJSON)
+
+	Printf("Processing %d files\n", 2)
+	Debug("decoded raw call")
+	Event(slog.LevelInfo, "  ✓ a.txt (2 calls)", "file processed", "file", "a.txt", "calls", 2)
+	Warn("sender is not mapped", "sender", "0x10000")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "file processed", record["msg"])
+	assert.Equal(t, "a.txt", record["file"])
+	assert.Equal(t, float64(2), record["calls"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "0x10000", record["sender"])
+}
+
+func TestConfigure_UnknownFormat(t *testing.T) {
+	assert.ErrorContains(t, Configure(slog.LevelInfo, "yaml"), `unknown log format "yaml"`)
+}
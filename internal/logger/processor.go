@@ -2,31 +2,50 @@ package logger
 
 import (
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
 )
 
 // ProcessingStats holds statistics about replay processing
 type ProcessingStats struct {
-	TotalFiles   int
-	SuccessCount int
-	FailureCount int
-	FailedFiles  []FailedFile
-	SuccessTests []SuccessTest
+	TotalFiles   int              `json:"totalFiles"`
+	SuccessCount int              `json:"successCount"`
+	FailureCount int              `json:"failureCount"`
+	FailedFiles  []FailedFile     `json:"failedFiles"`
+	SuccessTests []SuccessTest    `json:"tests"`
+	Duplicates   []DuplicateFiles `json:"duplicates"`
+	Warnings     []FileWarning    `json:"warnings"`
 }
 
 // FailedFile represents a file that failed to process
 type FailedFile struct {
-	FileName string
-	Error    string
+	FileName string `json:"file"`
+	Path     string `json:"path"`
+	Error    string `json:"error"`
 }
 
 // SuccessTest represents a successfully processed test
 type SuccessTest struct {
-	FileName     string
-	TestName     string
-	LastFunction string
-	CallCount    int
+	FileName     string `json:"file"`
+	Path         string `json:"path"`
+	TestName     string `json:"testName"`
+	LastFunction string `json:"lastFunction"`
+	CallCount    int    `json:"callCount"`
+}
+
+// DuplicateFiles represents reproducers collapsed into the test of another file
+type DuplicateFiles struct {
+	KeptFile    string   `json:"keptFile"`
+	MergedFiles []string `json:"mergedFiles"`
+	Similarity  float64  `json:"similarity"`
+}
+
+// FileWarning represents a problem with a file that was otherwise processed successfully
+type FileWarning struct {
+	FileName string `json:"file"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
 }
 
 // ProcessorLogger handles logging for replay processing
@@ -40,6 +59,8 @@ func NewProcessorLogger() *ProcessorLogger {
 		stats: ProcessingStats{
 			FailedFiles:  make([]FailedFile, 0),
 			SuccessTests: make([]SuccessTest, 0),
+			Duplicates:   make([]DuplicateFiles, 0),
+			Warnings:     make([]FileWarning, 0),
 		},
 	}
 }
@@ -48,28 +69,40 @@ func NewProcessorLogger() *ProcessorLogger {
 func (l *ProcessorLogger) LogFileStart(filePath string) {
 	l.stats.TotalFiles++
 	fileName := filepath.Base(filePath)
-	fmt.Printf("- Processing: %s\n", fileName)
+	Event(slog.LevelInfo, "- Processing: "+fileName, "processing file", "file", filePath)
 }
 
 // LogFileSuccess logs successful processing of a file
-func (l *ProcessorLogger) LogFileSuccess(filePath, testName, lastFunction string, callCount int) {
+func (l *ProcessorLogger) LogFileSuccess(filePath, lastFunction string, callCount int) {
 	l.stats.SuccessCount++
 	fileName := filepath.Base(filePath)
 
+	line := fmt.Sprintf("  ✓ %s (%d calls)", fileName, callCount)
+	if lastFunction != "" {
+		line = fmt.Sprintf("  ✓ %s (last: %s, %d calls)", fileName, lastFunction, callCount)
+	}
+	Event(slog.LevelInfo, line, "file processed", "file", filePath, "lastFunction", lastFunction, "calls", callCount)
+}
+
+// LogTest records the test function generated for a file, listed in the summary
+func (l *ProcessorLogger) LogTest(filePath, testName, lastFunction string, callCount int) {
 	l.stats.SuccessTests = append(l.stats.SuccessTests, SuccessTest{
-		FileName:     fileName,
+		FileName:     filepath.Base(filePath),
+		Path:         filePath,
 		TestName:     testName,
 		LastFunction: lastFunction,
 		CallCount:    callCount,
 	})
+}
 
-	if lastFunction != "" {
-		fmt.Printf("  ✓ %s -> %s (last: %s, %d calls)\n",
-			fileName, testName, lastFunction, callCount)
-	} else {
-		fmt.Printf("  ✓ %s -> %s (%d calls)\n",
-			fileName, testName, callCount)
-	}
+// LogFileWarning logs a problem with a file that was otherwise processed successfully
+func (l *ProcessorLogger) LogFileWarning(filePath, message string) {
+	l.stats.Warnings = append(l.stats.Warnings, FileWarning{
+		FileName: filepath.Base(filePath),
+		Path:     filePath,
+		Message:  message,
+	})
+	Event(slog.LevelWarn, fmt.Sprintf("  ! %s - %s", filepath.Base(filePath), message), message, "file", filePath)
 }
 
 // LogFileFailure logs failed processing of a file
@@ -79,45 +112,100 @@ func (l *ProcessorLogger) LogFileFailure(filePath string, err error) {
 
 	l.stats.FailedFiles = append(l.stats.FailedFiles, FailedFile{
 		FileName: fileName,
+		Path:     filePath,
 		Error:    err.Error(),
 	})
 
-	fmt.Printf("  ✗ %s - %v\n", fileName, err)
+	Event(slog.LevelWarn, fmt.Sprintf("  ✗ %s - %v", fileName, err), "file failed", "file", filePath, "error", err.Error())
+}
+
+// LogGenerationFailure records a file that was processed but whose test could not be
+// generated, moving it from the successes to the failures
+func (l *ProcessorLogger) LogGenerationFailure(filePath string, err error) {
+	tests := make([]SuccessTest, 0, len(l.stats.SuccessTests))
+	for _, test := range l.stats.SuccessTests {
+		if test.Path != filePath {
+			tests = append(tests, test)
+		}
+	}
+	if len(tests) < len(l.stats.SuccessTests) {
+		l.stats.SuccessCount--
+	}
+	l.stats.SuccessTests = tests
+	l.LogFileFailure(filePath, err)
 }
 
-// LogProcessingSummary logs a summary of all processing
+// LogDuplicates records files whose call sequences were collapsed into the test of another file
+func (l *ProcessorLogger) LogDuplicates(keptPath string, mergedPaths []string, similarity float64) {
+	merged := make([]string, len(mergedPaths))
+	for i, path := range mergedPaths {
+		merged[i] = filepath.Base(path)
+	}
+	l.stats.Duplicates = append(l.stats.Duplicates, DuplicateFiles{
+		KeptFile:    filepath.Base(keptPath),
+		MergedFiles: merged,
+		Similarity:  similarity,
+	})
+}
+
+// LogProcessingSummary logs a summary of all processing. JSON logs get a single record
+// with the counts, the details being available in a report.
 func (l *ProcessorLogger) LogProcessingSummary() {
-	fmt.Println("\n" + strings.Repeat("-", 50))
-	fmt.Println("PROCESSING SUMMARY")
-	fmt.Println(strings.Repeat("-", 50))
+	if format == FormatJSON {
+		Info("processing summary",
+			"files", l.stats.TotalFiles,
+			"success", l.stats.SuccessCount,
+			"failed", l.stats.FailureCount,
+			"tests", len(l.stats.SuccessTests),
+			"duplicates", len(l.stats.Duplicates),
+			"warnings", len(l.stats.Warnings))
+		return
+	}
+
+	Println("\n" + strings.Repeat("-", 50))
+	Println("PROCESSING SUMMARY")
+	Println(strings.Repeat("-", 50))
 
-	fmt.Printf("Total files: %d | Success: %d | Failed: %d\n",
+	Printf("Total files: %d | Success: %d | Failed: %d\n",
 		l.stats.TotalFiles, l.stats.SuccessCount, l.stats.FailureCount)
 
-	if l.stats.SuccessCount > 0 {
-		fmt.Println("\nGenerated tests:")
+	if len(l.stats.SuccessTests) > 0 {
+		Println("\nGenerated tests:")
 		for i, test := range l.stats.SuccessTests {
-			fmt.Printf("  %d. %s (last call: %s)\n",
-				i+1, test.TestName, test.LastFunction)
+			Printf("  %d. %s (%s, last call: %s)\n",
+				i+1, test.TestName, test.FileName, test.LastFunction)
+		}
+	}
+
+	if len(l.stats.Duplicates) > 0 {
+		Println("\nMerged duplicates:")
+		for i, duplicate := range l.stats.Duplicates {
+			if duplicate.Similarity < 1 {
+				Printf("  %d. %s <- %s (%.0f%% similar)\n",
+					i+1, duplicate.KeptFile, strings.Join(duplicate.MergedFiles, ", "), duplicate.Similarity*100)
+			} else {
+				Printf("  %d. %s <- %s (identical)\n",
+					i+1, duplicate.KeptFile, strings.Join(duplicate.MergedFiles, ", "))
+			}
 		}
 	}
 
 	if l.stats.FailureCount > 0 {
-		fmt.Println("\nFailed files:")
+		Println("\nFailed files:")
 		for i, failed := range l.stats.FailedFiles {
-			fmt.Printf("  %d. %s: %s\n", i+1, failed.FileName, failed.Error)
+			Printf("  %d. %s: %s\n", i+1, failed.FileName, failed.Error)
 		}
 	}
 
 	// Success rate
 	if l.stats.TotalFiles > 0 {
 		successRate := float64(l.stats.SuccessCount) / float64(l.stats.TotalFiles) * 100
-		fmt.Println()
-		fmt.Println(strings.Repeat("-", 50))
-		fmt.Printf("Success rate: %.1f%%\n", successRate)
+		Println()
+		Println(strings.Repeat("-", 50))
+		Printf("Success rate: %.1f%%\n", successRate)
 	}
 
-	fmt.Println(strings.Repeat("-", 50))
+	Println(strings.Repeat("-", 50))
 }
 
 // GetStats returns the current processing statistics
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReportWriter writes the statistics of a processing run in a machine-readable format
+type ReportWriter func(w io.Writer, stats ProcessingStats) error
+
+// reportWriters holds the report formats by name
+var reportWriters = map[string]ReportWriter{
+	"json":     writeJSONReport,
+	"junit":    writeJUnitReport,
+	"markdown": writeMarkdownReport,
+}
+
+// RegisterReportFormat adds a report format, replacing a format of the same name
+func RegisterReportFormat(name string, writer ReportWriter) error {
+	if name == "" || writer == nil {
+		return fmt.Errorf("report format needs a name and a writer")
+	}
+	reportWriters[name] = writer
+	return nil
+}
+
+// ReportFormats returns the names of the report formats in alphabetical order
+func ReportFormats() []string {
+	names := make([]string, 0, len(reportWriters))
+	for name := range reportWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteReport writes the statistics to w in the named format
+func WriteReport(w io.Writer, format string, stats ProcessingStats) error {
+	writer, ok := reportWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown report format %q (expected one of: %s)", format, strings.Join(ReportFormats(), ", "))
+	}
+	return writer(w, stats)
+}
+
+// writeJSONReport writes the statistics as indented JSON
+func writeJSONReport(w io.Writer, stats ProcessingStats) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(stats)
+}
+
+// junitTestSuite is the root element of a JUnit XML report
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is the conversion of one reproducer file
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is a failure or skip reason
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnitReport writes one test case per reproducer file: converted files pass, files
+// that failed to convert fail with the reason, and merged duplicates are skipped
+func writeJUnitReport(w io.Writer, stats ProcessingStats) error {
+	warnings := make(map[string][]string)
+	for _, warning := range stats.Warnings {
+		warnings[warning.Path] = append(warnings[warning.Path], warning.Message)
+	}
+
+	suite := junitTestSuite{Name: "runes"}
+	for _, test := range stats.SuccessTests {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      test.FileName,
+			ClassName: "reproducers",
+			SystemOut: strings.Join(append([]string{fmt.Sprintf("%s: %d calls, last call: %s", test.TestName, test.CallCount, test.LastFunction)}, warnings[test.Path]...), "\n"),
+		})
+	}
+	for _, duplicate := range stats.Duplicates {
+		for _, merged := range duplicate.MergedFiles {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      merged,
+				ClassName: "reproducers",
+				Skipped:   &junitMessage{Message: fmt.Sprintf("duplicate of %s", duplicate.KeptFile)},
+			})
+			suite.Skipped++
+		}
+	}
+	for _, failed := range stats.FailedFiles {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      failed.FileName,
+			ClassName: "reproducers",
+			Failure:   &junitMessage{Message: failed.Error},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeMarkdownReport writes the statistics as Markdown tables, e.g. for a CI job summary
+func writeMarkdownReport(w io.Writer, stats ProcessingStats) error {
+	var b strings.Builder
+	b.WriteString("## Reproducer conversion\n\n")
+	fmt.Fprintf(&b, "%d files: %d converted, %d failed\n", stats.TotalFiles, stats.SuccessCount, stats.FailureCount)
+
+	if len(stats.SuccessTests) > 0 {
+		b.WriteString("\n| File | Test | Calls | Last call |\n|---|---|---|---|\n")
+		for _, test := range stats.SuccessTests {
+			fmt.Fprintf(&b, "| %s | `%s` | %d | %s |\n", markdownCell(test.FileName), test.TestName, test.CallCount, markdownCell(test.LastFunction))
+		}
+	}
+
+	if len(stats.Duplicates) > 0 {
+		b.WriteString("\n| Kept | Merged duplicates | Similarity |\n|---|---|---|\n")
+		for _, duplicate := range stats.Duplicates {
+			fmt.Fprintf(&b, "| %s | %s | %.0f%% |\n", markdownCell(duplicate.KeptFile), markdownCell(strings.Join(duplicate.MergedFiles, ", ")), duplicate.Similarity*100)
+		}
+	}
+
+	if len(stats.Warnings) > 0 {
+		b.WriteString("\n| File | Warning |\n|---|---|\n")
+		for _, warning := range stats.Warnings {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCell(warning.FileName), markdownCell(warning.Message))
+		}
+	}
+
+	if len(stats.FailedFiles) > 0 {
+		b.WriteString("\n| Failed file | Error |\n|---|---|\n")
+		for _, failed := range stats.FailedFiles {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCell(failed.FileName), markdownCell(failed.Error))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleStats records a run with a converted file, a duplicate and a failure
+func sampleStats(t *testing.T) ProcessingStats {
+	previous := Output
+	Output = io.Discard
+	t.Cleanup(func() { Output = previous })
+
+	log := NewProcessorLogger()
+	for _, path := range []string{"repro/a.txt", "repro/b.txt", "repro/c.txt"} {
+		log.LogFileStart(path)
+	}
+	log.LogFileSuccess("repro/a.txt", "deposit", 2)
+	log.LogFileWarning("repro/a.txt", "1 transactions cannot be replayed")
+	log.LogFileSuccess("repro/b.txt", "deposit", 2)
+	log.LogFileFailure("repro/c.txt", assert.AnError)
+	log.LogDuplicates("repro/a.txt", []string{"repro/b.txt"}, 1)
+	log.LogTest("repro/a.txt", "test_replay_deposit", "deposit", 2)
+	return log.GetStats()
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, "json", sampleStats(t)))
+
+	var stats ProcessingStats
+	require.NoError(t, json.Unmarshal(out.Bytes(), &stats))
+	assert.Equal(t, 3, stats.TotalFiles)
+	assert.Equal(t, []SuccessTest{{FileName: "a.txt", Path: "repro/a.txt", TestName: "test_replay_deposit", LastFunction: "deposit", CallCount: 2}}, stats.SuccessTests)
+	assert.Equal(t, []FailedFile{{FileName: "c.txt", Path: "repro/c.txt", Error: assert.AnError.Error()}}, stats.FailedFiles)
+	assert.Equal(t, "1 transactions cannot be replayed", stats.Warnings[0].Message)
+}
+
+func TestLogGenerationFailure(t *testing.T) {
+	previous := Output
+	Output = io.Discard
+	t.Cleanup(func() { Output = previous })
+
+	log := NewProcessorLogger()
+	for _, path := range []string{"repro/a.txt", "repro/b.txt"} {
+		log.LogFileStart(path)
+		log.LogFileSuccess(path, "deposit", 1)
+	}
+	log.LogTest("repro/a.txt", "test_a", "deposit", 1)
+	log.LogTest("repro/b.txt", "test_b", "deposit", 1)
+
+	// A file whose test could not be generated is no longer reported as converted
+	log.LogGenerationFailure("repro/b.txt", assert.AnError)
+	stats := log.GetStats()
+	assert.Equal(t, 2, stats.TotalFiles)
+	assert.Equal(t, 1, stats.SuccessCount)
+	assert.Equal(t, 1, stats.FailureCount)
+	assert.Equal(t, []SuccessTest{{FileName: "a.txt", Path: "repro/a.txt", TestName: "test_a", LastFunction: "deposit", CallCount: 1}}, stats.SuccessTests)
+	assert.Equal(t, []FailedFile{{FileName: "b.txt", Path: "repro/b.txt", Error: assert.AnError.Error()}}, stats.FailedFiles)
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, "junit", sampleStats(t)))
+
+	var suite junitTestSuite
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suite))
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	require.Len(t, suite.TestCases, 3)
+	assert.Equal(t, "test_replay_deposit: 2 calls, last call: deposit\n1 transactions cannot be replayed", suite.TestCases[0].SystemOut)
+	assert.Equal(t, "duplicate of a.txt", suite.TestCases[1].Skipped.Message)
+	assert.Equal(t, assert.AnError.Error(), suite.TestCases[2].Failure.Message)
+}
+
+func TestWriteReport_Markdown(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, "markdown", sampleStats(t)))
+
+	report := out.String()
+	assert.Contains(t, report, "3 files: 2 converted, 1 failed")
+	assert.Contains(t, report, "| a.txt | `test_replay_deposit` | 2 | deposit |")
+	assert.Contains(t, report, "| a.txt | b.txt | 100% |")
+	assert.Contains(t, report, "| c.txt | "+assert.AnError.Error()+" |")
+}
+
+func TestWriteReport_Formats(t *testing.T) {
+	assert.ErrorContains(t, WriteReport(io.Discard, "yaml", ProcessingStats{}), `unknown report format "yaml"`)
+
+	require.NoError(t, RegisterReportFormat("count", func(w io.Writer, stats ProcessingStats) error {
+		_, err := io.WriteString(w, "converted")
+		return err
+	}))
+	t.Cleanup(func() { delete(reportWriters, "count") })
+
+	assert.Equal(t, []string{"count", "json", "junit", "markdown"}, ReportFormats())
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, "count", ProcessingStats{}))
+	assert.Equal(t, "converted", out.String())
+}
@@ -12,6 +12,9 @@ const (
 	DefaultPrefix = "ReplayTest"
 	DefaultSuffix = ".t.sol"
 	MaxFileCount  = 9999
+
+	// StdoutPath is the output path that writes the generated test to standard output
+	StdoutPath = "-"
 )
 
 // Config holds output configuration
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// DecodeRawCalls decodes raw calls whose selector matches a method of the ABI into
+// named function calls. Calls that do not match stay raw and are replayed as
+// low-level calls. It returns the updated calls and the number of decoded calls.
+func DecodeRawCalls(calls []types.ParsedCall, contractABI *abi.ABI) ([]types.ParsedCall, int) {
+	if contractABI == nil {
+		return calls, 0
+	}
+
+	result := make([]types.ParsedCall, len(calls))
+	decoded := 0
+	for i, call := range calls {
+		result[i] = call
+		if call.Kind != types.CallKindRaw {
+			continue
+		}
+
+		calldata, err := decodeHex(call.Calldata)
+		if err != nil {
+			continue
+		}
+
+		method, params, err := contractABI.DecodeCall(calldata)
+		if err != nil {
+			logger.Debug("raw call kept as calldata", "selector", selectorOf(calldata), "reason", err.Error())
+			continue
+		}
+		logger.Debug("decoded raw call", "selector", selectorOf(calldata), "function", method.Signature())
+
+		result[i].Kind = types.CallKindFunction
+		result[i].FunctionName = method.Name
+		result[i].Parameters = params
+		result[i].Calldata = ""
+		decoded++
+	}
+	return result, decoded
+}
+
+// DecodeCreations matches the creation code of create calls against the artifacts and
+// decodes their constructor arguments. Deployments of unknown contracts are left as is.
+// It returns the updated calls and the number of matched deployments.
+func DecodeCreations(calls []types.ParsedCall, artifacts *abi.ArtifactSet) ([]types.ParsedCall, int) {
+	if artifacts == nil {
+		return calls, 0
+	}
+
+	result := make([]types.ParsedCall, len(calls))
+	matched := 0
+	for i, call := range calls {
+		result[i] = call
+		if call.Kind != types.CallKindCreate || call.ContractName != "" {
+			continue
+		}
+
+		code, err := decodeHex(call.Calldata)
+		if err != nil {
+			continue
+		}
+
+		artifact, args := artifacts.MatchCreationCode(code)
+		if artifact == nil {
+			logger.Debug("creation code matches no artifact", "size", len(code))
+			continue
+		}
+
+		var inputTypes []string
+		if artifact.ABI.Constructor != nil {
+			inputTypes = abi.InputTypes(artifact.ABI.Constructor.Inputs)
+		}
+		params, err := abi.DecodeArguments(inputTypes, args)
+		if err != nil {
+			logger.Debug("constructor arguments cannot be decoded", "contract", artifact.Name, "reason", err.Error())
+			continue
+		}
+		logger.Debug("matched deployment", "contract", artifact.Name)
+
+		result[i].ContractName = artifact.Name
+		result[i].Parameters = params
+		matched++
+	}
+	return result, matched
+}
+
+// selectorOf returns the hex function selector of calldata, or all of it when shorter
+func selectorOf(calldata []byte) string {
+	if len(calldata) > 4 {
+		calldata = calldata[:4]
+	}
+	return "0x" + hex.EncodeToString(calldata)
+}
+
+// CountCalls returns the number of calls of the given kind
+func CountCalls(calls []types.ParsedCall, kind types.CallKind) int {
+	count := 0
+	for _, call := range calls {
+		if call.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// echidnaBytes decodes a ByteString from Echidna's JSON, which is either 0x-prefixed
+// hex or a string with one character per byte
+func echidnaBytes(contents interface{}) ([]byte, error) {
+	value, ok := contents.(string)
+	if !ok {
+		return nil, fmt.Errorf("bytes contents are not a string")
+	}
+
+	if digits, ok := strings.CutPrefix(value, "0x"); ok {
+		if decoded, err := hex.DecodeString(digits); err == nil {
+			return decoded, nil
+		}
+	}
+
+	result := make([]byte, 0, len(value))
+	for _, r := range value {
+		if r > 0xFF {
+			return nil, fmt.Errorf("bytes contents contain the non-byte character %q", r)
+		}
+		result = append(result, byte(r))
+	}
+	return result, nil
+}
+
+// decodeHex decodes a 0x-prefixed hex string
+func decodeHex(value string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex data: %q", value)
+	}
+	return decoded, nil
+}
+
+// rawCall marks a call as a low-level call with the given calldata
+func rawCall(call *types.ParsedCall, calldata []byte) {
+	call.Kind = types.CallKindRaw
+	call.FunctionName = ""
+	call.Parameters = []types.ParsedParam{}
+	call.Calldata = "0x" + hex.EncodeToString(calldata)
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+const (
+	FormatEchidna = "echidna"
+	FormatMedusa  = "medusa"
+	FormatFoundry = "foundry"
+)
+
+// Format describes a reproducer input format that can be auto-detected
+type Format struct {
+	Name   string
+	Detect func(data []byte) bool
+	Parse  func(data []byte) ([]types.ParsedCall, error)
+}
+
+// formats holds the known input formats in detection order. Echidna accepts anything,
+// so it stays last and registered formats are inserted before it.
+var (
+	formatsMu sync.RWMutex
+	formats   = []Format{
+		{Name: FormatMedusa, Detect: isMedusaSequence, Parse: parseMedusaData},
+		{Name: FormatFoundry, Detect: isFoundrySequence, Parse: parseFoundryData},
+		{Name: FormatEchidna, Detect: isEchidnaReproducer, Parse: parseEchidnaData},
+	}
+)
+
+// RegisterFormat adds an input format, detected before the Echidna fallback
+func RegisterFormat(format Format) error {
+	if format.Name == "" || format.Detect == nil || format.Parse == nil {
+		return fmt.Errorf("format requires a name, a detect and a parse function")
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	for _, existing := range formats {
+		if existing.Name == format.Name {
+			return fmt.Errorf("format %q is already registered", format.Name)
+		}
+	}
+
+	last := len(formats) - 1
+	formats = append(formats[:last:last], format, formats[last])
+	return nil
+}
+
+// FormatNames returns the names of the known input formats in detection order
+func FormatNames() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for _, format := range formats {
+		names = append(names, format.Name)
+	}
+	return names
+}
+
+// DetectFormat returns the name of the format the data is parsed as
+func DetectFormat(data []byte) string {
+	format, err := detectFormat(data)
+	if err != nil {
+		return ""
+	}
+	return format.Name
+}
+
+// detectFormat finds the first registered format that recognises the data
+func detectFormat(data []byte) (Format, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	for _, format := range formats {
+		if format.Detect(data) {
+			return format, nil
+		}
+	}
+	return Format{}, fmt.Errorf("unrecognised reproducer format")
+}
+
+// firstSequenceElement decodes the first element of a JSON array, reporting whether the array is empty
+func firstSequenceElement(data []byte) (map[string]json.RawMessage, bool, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, false, err
+	}
+	if len(elements) == 0 {
+		return nil, true, nil
+	}
+
+	var first map[string]json.RawMessage
+	if err := json.Unmarshal(elements[0], &first); err != nil {
+		return nil, false, err
+	}
+	return first, false, nil
+}
+
+// isEchidnaReproducer reports whether data looks like an Echidna reproducer.
+// Echidna is the fallback format, so anything that is not clearly another format is accepted.
+func isEchidnaReproducer(data []byte) bool {
+	return true
+}
+
+// isMedusaSequence reports whether data looks like a Medusa call sequence
+func isMedusaSequence(data []byte) bool {
+	first, empty, err := firstSequenceElement(bytes.TrimSpace(data))
+	if err != nil || empty {
+		return false
+	}
+
+	if _, ok := first["blockNumberDelay"]; ok {
+		return true
+	}
+	if _, ok := first["blockTimestampDelay"]; ok {
+		return true
+	}
+
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(first["call"], &call); err != nil {
+		return false
+	}
+	_, hasFrom := call["from"]
+	_, hasAbiValues := call["dataAbiValues"]
+	return hasFrom || hasAbiValues
+}
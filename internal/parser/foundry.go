@@ -0,0 +1,405 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// foundrySequenceHeader starts the failing call sequence in Foundry's invariant test output
+const foundrySequenceHeader = "[Sequence]"
+
+// foundryCallLine matches a call of a failing invariant sequence, e.g.
+// "sender=0x...1c28 addr=[test/Handler.sol:Handler]0x2e23...470b calldata=deposit(uint256) args=[3]".
+// Newer Foundry versions prefix the call with its "warp=" and "roll=" delays.
+var foundryCallLine = regexp.MustCompile(
+	`^((?:(?:warp|roll)=\d+\s+)*)sender=(0x[0-9a-fA-F]{40})\s+addr=\[[^\]]*\](0x[0-9a-fA-F]{40})\s+calldata=(\S+)\s+args=\[(.*)\]$`)
+
+// foundryDelay matches the delays of a call line
+var foundryDelay = regexp.MustCompile(`(warp|roll)=(\d+)`)
+
+// isFoundrySequence reports whether data looks like Foundry invariant output or a persisted failure
+func isFoundrySequence(data []byte) bool {
+	if isFoundryPersistedFailure(data) {
+		return true
+	}
+	return bytes.Contains(data, []byte("sender=0x")) && bytes.Contains(data, []byte(" calldata="))
+}
+
+// isFoundryPersistedFailure reports whether data is a failure persisted under cache/invariant/failures
+func isFoundryPersistedFailure(data []byte) bool {
+	var failure map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimSpace(data), &failure); err != nil {
+		return false
+	}
+	_, ok := failure["call_sequence"]
+	return ok
+}
+
+// parseFoundryData converts the failing call sequence printed by Foundry's invariant runner to parsed calls
+func parseFoundryData(data []byte) ([]types.ParsedCall, error) {
+	if isFoundryPersistedFailure(data) {
+		return parseFoundryPersistedFailure(data)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if count := strings.Count(string(data), foundrySequenceHeader); count > 1 {
+		return nil, fmt.Errorf("output contains %d failing sequences, save each %s block to its own file", count, foundrySequenceHeader)
+	}
+
+	// Skip to the sequence header when there is one, otherwise every call line belongs to the sequence
+	start := 0
+	for i, line := range lines {
+		// Newer versions append the shrinking result, e.g. "[Sequence] (original: 5, shrunk: 2)"
+		if strings.HasPrefix(strings.TrimSpace(line), foundrySequenceHeader) {
+			start = i + 1
+			break
+		}
+	}
+
+	var calls []types.ParsedCall
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		match := foundryCallLine.FindStringSubmatch(line)
+		if match == nil {
+			// The sequence ends at the first line that is not a call
+			if len(calls) > 0 {
+				break
+			}
+			continue
+		}
+
+		call, err := parseFoundryCall(match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse call %d: %w", len(calls), err)
+		}
+		calls = append(calls, call)
+	}
+
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("no calls found in Foundry sequence")
+	}
+	return calls, nil
+}
+
+// parseFoundryCall converts a matched call line to a parsed call
+func parseFoundryCall(match []string) (types.ParsedCall, error) {
+	delays, sender, target, signature, args := match[1], match[2], match[3], match[4], match[5]
+
+	call := types.ParsedCall{
+		Dst:   target,
+		Src:   sender,
+		Value: "0x0",
+	}
+
+	timeDelay, blockDelay := new(big.Int), new(big.Int)
+	for _, delay := range foundryDelay.FindAllStringSubmatch(delays, -1) {
+		if delay[1] == "warp" {
+			timeDelay.SetString(delay[2], 10)
+		} else {
+			blockDelay.SetString(delay[2], 10)
+		}
+	}
+	applyDelay(&call, timeDelay, blockDelay)
+
+	// Calls to functions missing from the target ABI are printed as raw calldata
+	if strings.HasPrefix(signature, "0x") {
+		calldata, err := decodeHex(signature)
+		if err != nil {
+			return types.ParsedCall{}, err
+		}
+		rawCall(&call, calldata)
+		return call, nil
+	}
+
+	functionName, argTypes, err := abi.ParseSignature(signature)
+	if err != nil {
+		return types.ParsedCall{}, err
+	}
+
+	values, err := splitFoundryValues(args)
+	if err != nil {
+		return types.ParsedCall{}, fmt.Errorf("invalid arguments of %s: %w", signature, err)
+	}
+	if len(values) != len(argTypes) {
+		return types.ParsedCall{}, fmt.Errorf("method %s expects %d arguments but %d were provided",
+			signature, len(argTypes), len(values))
+	}
+
+	params := []types.ParsedParam{}
+	for i, argType := range argTypes {
+		param, err := parseFoundryValue(argType, values[i])
+		if err != nil {
+			return types.ParsedCall{}, fmt.Errorf("failed to parse parameter %d of %s: %w", i, functionName, err)
+		}
+		params = append(params, param)
+	}
+
+	call.FunctionName = functionName
+	call.Parameters = params
+	return call, nil
+}
+
+// parseFoundryPersistedFailure converts a failure persisted under cache/invariant/failures to raw calls,
+// which DecodeRawCalls turns into function calls when the target ABI is known
+func parseFoundryPersistedFailure(data []byte) ([]types.ParsedCall, error) {
+	var failure types.FoundryPersistedFailure
+	if err := unmarshalNumbers(data, &failure); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	var calls []types.ParsedCall
+	for i, tx := range failure.CallSequence {
+		calldata, err := decodeHex(tx.CallDetails.Calldata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse call %d: %w", i, err)
+		}
+
+		call := types.ParsedCall{
+			Dst:   tx.CallDetails.Target,
+			Src:   tx.Sender,
+			Value: "0x0",
+		}
+
+		timeDelay, blockDelay := new(big.Int), new(big.Int)
+		if tx.Warp != nil {
+			if timeDelay, err = foundryQuantity(tx.Warp); err != nil {
+				return nil, fmt.Errorf("failed to parse call %d: invalid warp: %w", i, err)
+			}
+		}
+		if tx.Roll != nil {
+			if blockDelay, err = foundryQuantity(tx.Roll); err != nil {
+				return nil, fmt.Errorf("failed to parse call %d: invalid roll: %w", i, err)
+			}
+		}
+		applyDelay(&call, timeDelay, blockDelay)
+
+		rawCall(&call, calldata)
+		calls = append(calls, call)
+	}
+
+	return calls, nil
+}
+
+// foundryQuantity parses a persisted number, serialized as a hex string or a JSON number
+func foundryQuantity(value interface{}) (*big.Int, error) {
+	decimal, err := medusaIntegerToDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := new(big.Int).SetString(decimal, 10)
+	return n, nil
+}
+
+// parseFoundryValue converts a value formatted by Foundry of the given Solidity type
+func parseFoundryValue(solType string, text string) (types.ParsedParam, error) {
+	switch {
+	case abi.IsArrayType(solType):
+		return parseFoundryArray(solType, text)
+	case abi.IsTupleType(solType):
+		return parseFoundryTuple(solType, text)
+	case strings.HasPrefix(solType, "uint"), strings.HasPrefix(solType, "int"):
+		// Large numbers are annotated with their scientific notation, e.g. "1000000000000000000 [1e18]"
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return types.ParsedParam{}, fmt.Errorf("missing integer value")
+		}
+		n, ok := new(big.Int).SetString(fields[0], 0)
+		if !ok {
+			return types.ParsedParam{}, fmt.Errorf("invalid integer value: %q", fields[0])
+		}
+		return types.ParsedParam{Type: solType, Value: n.String()}, nil
+	case solType == "address":
+		return parseAddressParameter([]interface{}{text})
+	case solType == "bool":
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("invalid bool value: %q", text)
+		}
+		return parseBoolParameter([]interface{}{value})
+	case solType == "string":
+		value, err := unquoteFoundryString(text)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		return parseStringParameter([]interface{}{value})
+	case strings.HasPrefix(solType, "bytes"):
+		return types.ParsedParam{Type: solType, Value: text}, nil
+	default:
+		return types.ParsedParam{}, fmt.Errorf("unsupported ABI type: %s", solType)
+	}
+}
+
+// parseFoundryArray converts a bracketed value list to a fixed or dynamic array parameter
+func parseFoundryArray(solType string, text string) (types.ParsedParam, error) {
+	elemType, length, err := abi.SplitArrayType(solType)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	inner, ok := trimDelimiters(text, '[', ']')
+	if !ok {
+		return types.ParsedParam{}, fmt.Errorf("array value for %s is not bracketed: %q", solType, text)
+	}
+
+	values, err := splitFoundryValues(inner)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	if length != "" && length != strconv.Itoa(len(values)) {
+		return types.ParsedParam{}, fmt.Errorf("array %s has %d elements", solType, len(values))
+	}
+
+	elements := make([]types.ParsedParam, 0, len(values))
+	for i, value := range values {
+		element, err := parseFoundryValue(elemType, value)
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("array element %d: %w", i, err)
+		}
+		elements = append(elements, element)
+	}
+
+	return types.ParsedParam{Type: solType, Elements: elements}, nil
+}
+
+// parseFoundryTuple converts a parenthesised value list to a tuple parameter
+func parseFoundryTuple(solType string, text string) (types.ParsedParam, error) {
+	componentTypes, err := abi.SplitTupleType(solType)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	inner, ok := trimDelimiters(text, '(', ')')
+	if !ok {
+		return types.ParsedParam{}, fmt.Errorf("tuple value for %s is not parenthesised: %q", solType, text)
+	}
+
+	values, err := splitFoundryValues(inner)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	if len(values) != len(componentTypes) {
+		return types.ParsedParam{}, fmt.Errorf("tuple %s has %d components", solType, len(values))
+	}
+
+	components := make([]types.ParsedParam, 0, len(values))
+	for i, componentType := range componentTypes {
+		component, err := parseFoundryValue(componentType, values[i])
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("tuple component %d: %w", i, err)
+		}
+		components = append(components, component)
+	}
+
+	return types.ParsedParam{Type: solType, Elements: components}, nil
+}
+
+// splitFoundryValues splits a comma separated value list, ignoring commas nested in
+// brackets, parentheses and quoted strings
+func splitFoundryValues(list string) ([]string, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+
+	var values []string
+	depth := 0
+	start := 0
+	inString := false
+
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced brackets in %q", list)
+			}
+		case ',':
+			if depth == 0 {
+				values = append(values, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 || inString {
+		return nil, fmt.Errorf("unbalanced brackets in %q", list)
+	}
+	return append(values, strings.TrimSpace(list[start:])), nil
+}
+
+// trimDelimiters strips the opening and closing delimiter around a value
+func trimDelimiters(text string, open, close byte) (string, bool) {
+	if len(text) < 2 || text[0] != open || text[len(text)-1] != close {
+		return "", false
+	}
+	return text[1 : len(text)-1], true
+}
+
+// unquoteFoundryString decodes a string printed with Rust's debug formatting,
+// which escapes like Go except for "\0" and "\u{...}"
+func unquoteFoundryString(text string) (string, error) {
+	if len(text) < 2 || text[0] != '"' || text[len(text)-1] != '"' {
+		return "", fmt.Errorf("string value is not quoted: %q", text)
+	}
+
+	var b strings.Builder
+	body := text[1 : len(text)-1]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' || i+1 == len(body) {
+			b.WriteByte(body[i])
+			continue
+		}
+
+		i++
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '0':
+			b.WriteByte(0)
+		case 'u':
+			end := strings.IndexByte(body[i:], '}')
+			if !strings.HasPrefix(body[i:], "u{") || end < 0 {
+				return "", fmt.Errorf("invalid unicode escape in %q", text)
+			}
+			code, err := strconv.ParseUint(body[i+2:i+end], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in %q", text)
+			}
+			b.WriteRune(rune(code))
+			i += end
+		default:
+			// \\, \" and \'
+			b.WriteByte(body[i])
+		}
+	}
+	return b.String(), nil
+}
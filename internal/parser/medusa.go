@@ -0,0 +1,280 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/logger"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// errKeyedTuple reports a tuple value keyed by field name, which cannot be ordered without the contract ABI
+var errKeyedTuple = errors.New("tuple value is keyed by field name")
+
+// parseMedusaData decodes a Medusa call sequence and converts it to parsed calls
+func parseMedusaData(data []byte) ([]types.ParsedCall, error) {
+	var sequence types.MedusaCallSequence
+	if err := unmarshalNumbers(data, &sequence); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return parseMedusaSequence(sequence)
+}
+
+// unmarshalNumbers decodes JSON like json.Unmarshal, but keeps numbers as json.Number
+// so that integers above 2^53 keep their precision
+func unmarshalNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// parseMedusaSequence converts Medusa call sequence elements to parsed calls
+func parseMedusaSequence(sequence types.MedusaCallSequence) ([]types.ParsedCall, error) {
+	var calls []types.ParsedCall
+
+	for i, element := range sequence {
+		call, err := parseMedusaElement(element)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse call %d: %w", i, err)
+		}
+
+		if call != nil {
+			calls = append(calls, *call)
+		}
+	}
+
+	return calls, nil
+}
+
+// parseMedusaElement converts a single Medusa call sequence element to a parsed call
+func parseMedusaElement(element types.MedusaCallSequenceElement) (*types.ParsedCall, error) {
+	value, err := medusaQuantityToHex(element.Call.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	gas, err := medusaQuantityToInt64(element.Call.GasLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas limit: %w", err)
+	}
+
+	gasPrice, err := medusaQuantityToHex(element.Call.GasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas price: %w", err)
+	}
+
+	call := &types.ParsedCall{
+		Parameters: []types.ParsedParam{},
+		Dst:        element.Call.To,
+		Src:        element.Call.From,
+		Value:      value,
+		Gas:        gas,
+		GasPrice:   gasPrice,
+	}
+
+	applyDelay(call,
+		new(big.Int).SetUint64(element.BlockTimestampDelay),
+		new(big.Int).SetUint64(element.BlockNumberDelay))
+
+	calldata, err := decodeHex(element.Call.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %w", err)
+	}
+
+	// Calls without decoded ABI values are replayed from their raw calldata
+	if element.Call.DataAbiValues == nil {
+		if len(calldata) > 0 {
+			logger.Debug("call without decoded ABI values replayed as raw calldata", "sender", call.Src, "selector", selectorOf(calldata))
+			rawCall(call, calldata)
+			return call, nil
+		}
+		if call.HasDelay || call.HasBlockDelay {
+			return call, nil
+		}
+		logger.Debug("dropped empty call without delay", "sender", call.Src)
+		return nil, nil
+	}
+
+	functionName, argTypes, err := abi.ParseSignature(element.Call.DataAbiValues.MethodSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	inputValues := element.Call.DataAbiValues.InputValues
+	if len(inputValues) != len(argTypes) {
+		return nil, fmt.Errorf("method %s expects %d arguments but %d were provided",
+			element.Call.DataAbiValues.MethodSignature, len(argTypes), len(inputValues))
+	}
+
+	var params []types.ParsedParam
+	for i, argType := range argTypes {
+		param, err := parseMedusaValue(argType, inputValues[i])
+		// Medusa keys tuples by field name, so their values are only known through the calldata
+		if errors.Is(err, errKeyedTuple) && len(calldata) > 0 {
+			logger.Debug("call with keyed tuple values replayed as raw calldata", "sender", call.Src, "function", functionName)
+			rawCall(call, calldata)
+			return call, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameter %d of %s: %w", i, functionName, err)
+		}
+		params = append(params, param)
+	}
+
+	call.FunctionName = functionName
+	call.Parameters = params
+	return call, nil
+}
+
+// parseMedusaValue converts a JSON encoded Medusa ABI value of the given Solidity type
+func parseMedusaValue(solType string, value interface{}) (types.ParsedParam, error) {
+	switch {
+	case abi.IsArrayType(solType):
+		return parseMedusaArray(solType, value)
+	case abi.IsTupleType(solType):
+		return parseMedusaTuple(solType, value)
+	case strings.HasPrefix(solType, "uint"), strings.HasPrefix(solType, "int"):
+		valueStr, err := medusaIntegerToDecimal(value)
+		if err != nil {
+			return types.ParsedParam{}, err
+		}
+		return types.ParsedParam{Type: solType, Value: valueStr}, nil
+	case solType == "address":
+		return parseAddressParameter([]interface{}{value})
+	case solType == "bool":
+		return parseBoolParameter([]interface{}{value})
+	case solType == "string":
+		return parseStringParameter([]interface{}{value})
+	case strings.HasPrefix(solType, "bytes"):
+		valueStr, ok := value.(string)
+		if !ok {
+			return types.ParsedParam{}, fmt.Errorf("bytes value is not a string")
+		}
+		return types.ParsedParam{Type: solType, Value: valueStr}, nil
+	default:
+		return types.ParsedParam{}, fmt.Errorf("unsupported ABI type: %s", solType)
+	}
+}
+
+// parseMedusaArray converts a JSON array to a fixed or dynamic array parameter
+func parseMedusaArray(solType string, value interface{}) (types.ParsedParam, error) {
+	elemType, length, err := abi.SplitArrayType(solType)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return types.ParsedParam{}, fmt.Errorf("array value for %s is not a JSON array", solType)
+	}
+
+	if length != "" && length != strconv.Itoa(len(values)) {
+		return types.ParsedParam{}, fmt.Errorf("array %s has %d elements", solType, len(values))
+	}
+
+	elements := make([]types.ParsedParam, 0, len(values))
+	for i, v := range values {
+		element, err := parseMedusaValue(elemType, v)
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("array element %d: %w", i, err)
+		}
+		elements = append(elements, element)
+	}
+
+	return types.ParsedParam{Type: solType, Elements: elements}, nil
+}
+
+// parseMedusaTuple converts a positional JSON array to a tuple parameter. Tuples encoded as
+// objects are keyed by field name and cannot be ordered without the contract ABI.
+func parseMedusaTuple(solType string, value interface{}) (types.ParsedParam, error) {
+	componentTypes, err := abi.SplitTupleType(solType)
+	if err != nil {
+		return types.ParsedParam{}, err
+	}
+
+	if _, ok := value.(map[string]interface{}); ok {
+		return types.ParsedParam{}, fmt.Errorf("%s: %w", solType, errKeyedTuple)
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return types.ParsedParam{}, fmt.Errorf("tuple value for %s is not a positional JSON array", solType)
+	}
+
+	if len(values) != len(componentTypes) {
+		return types.ParsedParam{}, fmt.Errorf("tuple %s has %d components", solType, len(values))
+	}
+
+	components := make([]types.ParsedParam, 0, len(values))
+	for i, componentType := range componentTypes {
+		component, err := parseMedusaValue(componentType, values[i])
+		if err != nil {
+			return types.ParsedParam{}, fmt.Errorf("tuple component %d: %w", i, err)
+		}
+		components = append(components, component)
+	}
+
+	return types.ParsedParam{Type: solType, Elements: components}, nil
+}
+
+// medusaIntegerToDecimal normalises a Medusa integer value to a decimal string
+func medusaIntegerToDecimal(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return "", fmt.Errorf("invalid integer value: %q", v)
+		}
+		return n.String(), nil
+	case json.Number:
+		n, ok := new(big.Int).SetString(v.String(), 10)
+		if !ok {
+			return "", fmt.Errorf("invalid integer value: %s", v)
+		}
+		return n.String(), nil
+	default:
+		return "", fmt.Errorf("integer value has unexpected type %T", value)
+	}
+}
+
+// medusaQuantityToHex normalises a Medusa quantity (number or hex string) to a 0x-prefixed hex string
+func medusaQuantityToHex(value interface{}) (string, error) {
+	if value == nil {
+		return "0x0", nil
+	}
+
+	decimal, err := medusaIntegerToDecimal(value)
+	if err != nil {
+		return "", err
+	}
+
+	n, _ := new(big.Int).SetString(decimal, 10)
+	return "0x" + n.Text(16), nil
+}
+
+// medusaQuantityToInt64 normalises a Medusa quantity (number or hex string) to an int64
+func medusaQuantityToInt64(value interface{}) (int64, error) {
+	if value == nil {
+		return 0, nil
+	}
+
+	decimal, err := medusaIntegerToDecimal(value)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(decimal, 10, 64)
+}
@@ -1,17 +1,21 @@
 package parser
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/Enigma-Dark/runes/internal/logger"
 	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
 )
 
-// ParseReproducerFile parses an Echidna reproducer file
+// ParseReproducerFile parses an Echidna reproducer or Medusa call sequence file
 func ParseReproducerFile(filepath string) ([]types.ParsedCall, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -19,11 +23,35 @@ func ParseReproducerFile(filepath string) ([]types.ParsedCall, error) {
 	}
 	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	calls, err := ParseReproducer(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", filepath, err)
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath, err)
 	}
+	return calls, nil
+}
+
+// ParseReproducer reads a reproducer in any registered format and converts it to parsed calls
+func ParseReproducer(r io.Reader) ([]types.ParsedCall, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reproducer: %w", err)
+	}
+
+	return ParseData(data)
+}
+
+// ParseData converts a reproducer in any registered format to parsed calls
+func ParseData(data []byte) ([]types.ParsedCall, error) {
+	format, err := detectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Parse(data)
+}
 
+// parseEchidnaData decodes an Echidna reproducer and converts it to parsed calls
+func parseEchidnaData(data []byte) ([]types.ParsedCall, error) {
 	var reproducer types.EchidnaReproducer
 	if err := json.Unmarshal(data, &reproducer); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
@@ -39,8 +67,13 @@ func parseTransactions(transactions types.EchidnaReproducer) ([]types.ParsedCall
 	for _, tx := range transactions {
 		// Handle NoCall transactions (they represent pure time delays)
 		if tx.Call.Tag == "NoCall" {
-			hasDelay, delayValue := parseDelay(tx.Delay)
-			if hasDelay {
+			timeDelay, blockDelay, err := parseDelay(tx.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse delay: %w", err)
+			}
+			if timeDelay.Sign() == 0 && blockDelay.Sign() == 0 {
+				logger.Debug("dropped NoCall transaction without delay", "sender", tx.Src)
+			} else {
 				delayCall := types.ParsedCall{
 					FunctionName: "", // Empty function name for pure delays
 					Parameters:   []types.ParsedParam{},
@@ -49,19 +82,27 @@ func parseTransactions(transactions types.EchidnaReproducer) ([]types.ParsedCall
 					Value:        tx.Value,
 					Gas:          tx.Gas,
 					GasPrice:     tx.GasPrice,
-					HasDelay:     true,
-					DelayValue:   delayValue,
 				}
+				applyDelay(&delayCall, timeDelay, blockDelay)
 				calls = append(calls, delayCall)
 			}
 			continue
 		}
 
-		if tx.Call.Tag != "SolCall" {
-			continue // Skip non-Solidity calls
+		var call *types.ParsedCall
+		var err error
+		switch tx.Call.Tag {
+		case "SolCall":
+			call, err = parseCall(tx)
+		case "SolCalldata":
+			call, err = parseCalldataTransaction(tx)
+		case "SolCreate":
+			call, err = parseCreateTransaction(tx)
+		default:
+			// Kept in the sequence so the transaction is reported instead of silently dropped
+			logger.Debug("unsupported transaction kept as a comment", "tag", tx.Call.Tag, "sender", tx.Src)
+			call, err = parseUnsupportedTransaction(tx, fmt.Sprintf("%s transactions are not supported", tx.Call.Tag))
 		}
-
-		call, err := parseCall(tx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse call: %w", err)
 		}
@@ -74,20 +115,74 @@ func parseTransactions(transactions types.EchidnaReproducer) ([]types.ParsedCall
 	return calls, nil
 }
 
+// parseCalldataTransaction converts a SolCalldata transaction to a raw call
+func parseCalldataTransaction(tx types.Transaction) (*types.ParsedCall, error) {
+	calldata, err := echidnaBytes(tx.Call.Contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %w", err)
+	}
+
+	call, err := parseUnsupportedTransaction(tx, "")
+	if err != nil {
+		return nil, err
+	}
+	rawCall(call, calldata)
+	return call, nil
+}
+
+// parseCreateTransaction converts a SolCreate transaction to a deployment of its creation code
+func parseCreateTransaction(tx types.Transaction) (*types.ParsedCall, error) {
+	code, err := echidnaBytes(tx.Call.Contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creation code: %w", err)
+	}
+
+	call, err := parseUnsupportedTransaction(tx, "")
+	if err != nil {
+		return nil, err
+	}
+	call.Kind = types.CallKindCreate
+	call.Calldata = "0x" + hex.EncodeToString(code)
+	return call, nil
+}
+
+// parseUnsupportedTransaction keeps the sender and delays of a transaction that cannot be replayed
+func parseUnsupportedTransaction(tx types.Transaction, note string) (*types.ParsedCall, error) {
+	timeDelay, blockDelay, err := parseDelay(tx.Delay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse delay: %w", err)
+	}
+
+	call := &types.ParsedCall{
+		Kind:       types.CallKindUnsupported,
+		Parameters: []types.ParsedParam{},
+		Dst:        tx.Dst,
+		Src:        tx.Src,
+		Value:      tx.Value,
+		Gas:        tx.Gas,
+		GasPrice:   tx.GasPrice,
+		Note:       note,
+	}
+	applyDelay(call, timeDelay, blockDelay)
+
+	return call, nil
+}
+
 // parseCall converts a transaction to a parsed call
 func parseCall(tx types.Transaction) (*types.ParsedCall, error) {
-	if len(tx.Call.Contents) < 2 {
+	contents, ok := tx.Call.Contents.([]interface{})
+	if !ok || len(contents) < 2 {
 		return nil, fmt.Errorf("invalid call contents")
 	}
 
 	// Extract function name
-	functionName, ok := tx.Call.Contents[0].(string)
+	functionName, ok := contents[0].(string)
 	if !ok {
 		return nil, fmt.Errorf("function name is not a string")
 	}
 
 	// Extract parameters
-	paramsInterface, ok := tx.Call.Contents[1].([]interface{})
+	paramsInterface, ok := contents[1].([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("parameters are not an array")
 	}
@@ -102,9 +197,12 @@ func parseCall(tx types.Transaction) (*types.ParsedCall, error) {
 	}
 
 	// Parse delay information
-	hasDelay, delayValue := parseDelay(tx.Delay)
+	timeDelay, blockDelay, err := parseDelay(tx.Delay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse delay: %w", err)
+	}
 
-	return &types.ParsedCall{
+	call := &types.ParsedCall{
 		FunctionName: functionName,
 		Parameters:   params,
 		Dst:          tx.Dst,
@@ -112,44 +210,42 @@ func parseCall(tx types.Transaction) (*types.ParsedCall, error) {
 		Value:        tx.Value,
 		Gas:          tx.Gas,
 		GasPrice:     tx.GasPrice,
-		HasDelay:     hasDelay,
-		DelayValue:   delayValue,
-	}, nil
+	}
+	applyDelay(call, timeDelay, blockDelay)
+
+	return call, nil
 }
 
-// parseDelay extracts delay information from the delay field
-func parseDelay(delay []string) (bool, string) {
-	if len(delay) < 2 {
-		return false, "0"
-	}
+// parseDelay extracts the time and block delays from the delay field.
+// Echidna encodes the delay as a pair of hex strings: [time delay in seconds, block delay].
+func parseDelay(delay []string) (timeDelay *big.Int, blockDelay *big.Int, err error) {
+	timeDelay, blockDelay = new(big.Int), new(big.Int)
 
-	// The delay is represented as two hex strings
-	// We'll use the first one and convert from hex to decimal seconds
-	delayHex := delay[0]
-	if delayHex == "0x0000000000000000000000000000000000000000000000000000000000000000" || delayHex == "0x0" {
-		return false, "0"
+	if len(delay) > 0 {
+		if timeDelay, err = utils.ParseHexQuantity(delay[0]); err != nil {
+			return nil, nil, fmt.Errorf("invalid time delay: %w", err)
+		}
 	}
 
-	// Convert hex to decimal
-	// Remove 0x prefix if present
-	if strings.HasPrefix(delayHex, "0x") {
-		delayHex = delayHex[2:]
+	if len(delay) > 1 {
+		if blockDelay, err = utils.ParseHexQuantity(delay[1]); err != nil {
+			return nil, nil, fmt.Errorf("invalid block delay: %w", err)
+		}
 	}
 
-	// Parse hex string - we'll simplify by taking a reasonable subset
-	// For very large hex values, we'll use a simplified approach
-	if len(delayHex) > 8 {
-		// Take last 8 characters for reasonable delay values
-		delayHex = delayHex[len(delayHex)-8:]
-	}
+	return timeDelay, blockDelay, nil
+}
 
-	// Convert to integer
-	delayInt, err := strconv.ParseInt(delayHex, 16, 64)
-	if err != nil || delayInt == 0 {
-		return false, "0"
+// applyDelay records the time and block delays on a parsed call
+func applyDelay(call *types.ParsedCall, timeDelay, blockDelay *big.Int) {
+	if timeDelay.Sign() > 0 {
+		call.HasDelay = true
+		call.DelayValue = timeDelay.String()
+	}
+	if blockDelay.Sign() > 0 {
+		call.HasBlockDelay = true
+		call.BlockDelayValue = blockDelay.String()
 	}
-
-	return true, strconv.FormatInt(delayInt, 10)
 }
 
 // parseParameter converts a raw parameter to a parsed parameter
@@ -176,6 +272,23 @@ func parseParameter(paramInterface interface{}) (types.ParsedParam, error) {
 		}
 	}
 
+	// AbiBytesDynamic carries its bytes directly as contents
+	if tag == "AbiBytesDynamic" {
+		value, ok := contents.(string)
+		if !ok {
+			return types.ParsedParam{}, fmt.Errorf("dynamic bytes value is not a string")
+		}
+		return types.ParsedParam{
+			Type:  "bytes",
+			Value: value,
+		}, nil
+	}
+
+	// Echidna writes addresses and strings bare, older reproducers wrap them in an array
+	if value, ok := contents.(string); ok && (tag == "AbiAddress" || tag == "AbiString") {
+		contents = []interface{}{value}
+	}
+
 	// For other types, contents should be an array
 	contentsArray, ok := contents.([]interface{})
 	if !ok {
@@ -200,6 +313,12 @@ func parseParameter(paramInterface interface{}) (types.ParsedParam, error) {
 		return parseBytesParameter(contentsArray)
 	case "AbiString":
 		return parseStringParameter(contentsArray)
+	case "AbiArrayDynamic":
+		return parseArrayDynamicParameter(contentsArray)
+	case "AbiArray":
+		return parseArrayParameter(contentsArray)
+	case "AbiTuple":
+		return parseTupleParameter(contentsArray)
 	default:
 		return types.ParsedParam{}, fmt.Errorf("unsupported ABI type: %s", tag)
 	}
@@ -351,3 +470,162 @@ func parseStringParameter(contents []interface{}) (types.ParsedParam, error) {
 		Value: fmt.Sprintf(`"%s"`, value), // Wrap in quotes for Solidity
 	}, nil
 }
+
+// parseArrayDynamicParameter parses a dynamic array parameter: [elementType, [values...]]
+func parseArrayDynamicParameter(contents []interface{}) (types.ParsedParam, error) {
+	if len(contents) < 2 {
+		return types.ParsedParam{}, fmt.Errorf("dynamic array parameter needs 2 elements")
+	}
+
+	elemType, err := parseAbiType(contents[0])
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid dynamic array element type: %w", err)
+	}
+
+	elements, err := parseParameterList(contents[1])
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid dynamic array values: %w", err)
+	}
+
+	return types.ParsedParam{
+		Type:     elemType + "[]",
+		Elements: elements,
+	}, nil
+}
+
+// parseArrayParameter parses a fixed-size array parameter: [length, elementType, [values...]]
+func parseArrayParameter(contents []interface{}) (types.ParsedParam, error) {
+	if len(contents) < 3 {
+		return types.ParsedParam{}, fmt.Errorf("array parameter needs 3 elements")
+	}
+
+	length, ok := contents[0].(float64)
+	if !ok {
+		return types.ParsedParam{}, fmt.Errorf("array length is not a number")
+	}
+
+	elemType, err := parseAbiType(contents[1])
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid array element type: %w", err)
+	}
+
+	elements, err := parseParameterList(contents[2])
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid array values: %w", err)
+	}
+
+	if len(elements) != int(length) {
+		return types.ParsedParam{}, fmt.Errorf("array declares %d elements but has %d", int(length), len(elements))
+	}
+
+	return types.ParsedParam{
+		Type:     fmt.Sprintf("%s[%d]", elemType, int(length)),
+		Elements: elements,
+	}, nil
+}
+
+// parseTupleParameter parses a tuple parameter: [component values...]
+func parseTupleParameter(contents []interface{}) (types.ParsedParam, error) {
+	components, err := parseParameterList(contents)
+	if err != nil {
+		return types.ParsedParam{}, fmt.Errorf("invalid tuple component: %w", err)
+	}
+
+	componentTypes := make([]string, 0, len(components))
+	for _, component := range components {
+		componentTypes = append(componentTypes, component.Type)
+	}
+
+	return types.ParsedParam{
+		Type:     "(" + strings.Join(componentTypes, ",") + ")",
+		Elements: components,
+	}, nil
+}
+
+// parseParameterList parses a JSON array of ABI values
+func parseParameterList(list interface{}) ([]types.ParsedParam, error) {
+	values, ok := list.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("values are not an array")
+	}
+
+	params := make([]types.ParsedParam, 0, len(values))
+	for i, value := range values {
+		param, err := parseParameter(value)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// parseAbiType converts an Echidna ABI type descriptor (e.g. {"tag": "AbiUIntType", "contents": 256})
+// to its Solidity type name
+func parseAbiType(typeInterface interface{}) (string, error) {
+	typeMap, ok := typeInterface.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("type is not a map")
+	}
+
+	tag, ok := typeMap["tag"].(string)
+	if !ok {
+		return "", fmt.Errorf("type tag is not a string")
+	}
+
+	contents := typeMap["contents"]
+
+	switch tag {
+	case "AbiUIntType", "AbiIntType", "AbiBytesType":
+		size, ok := contents.(float64)
+		if !ok {
+			return "", fmt.Errorf("size of %s is not a number", tag)
+		}
+		prefix := map[string]string{"AbiUIntType": "uint", "AbiIntType": "int", "AbiBytesType": "bytes"}[tag]
+		return fmt.Sprintf("%s%d", prefix, int(size)), nil
+	case "AbiAddressType":
+		return "address", nil
+	case "AbiBoolType":
+		return "bool", nil
+	case "AbiBytesDynamicType":
+		return "bytes", nil
+	case "AbiStringType":
+		return "string", nil
+	case "AbiArrayDynamicType":
+		elemType, err := parseAbiType(contents)
+		if err != nil {
+			return "", err
+		}
+		return elemType + "[]", nil
+	case "AbiArrayType":
+		parts, ok := contents.([]interface{})
+		if !ok || len(parts) < 2 {
+			return "", fmt.Errorf("array type needs a length and an element type")
+		}
+		length, ok := parts[0].(float64)
+		if !ok {
+			return "", fmt.Errorf("array type length is not a number")
+		}
+		elemType, err := parseAbiType(parts[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s[%d]", elemType, int(length)), nil
+	case "AbiTupleType":
+		parts, ok := contents.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("tuple type components are not an array")
+		}
+		componentTypes := make([]string, 0, len(parts))
+		for _, part := range parts {
+			componentType, err := parseAbiType(part)
+			if err != nil {
+				return "", err
+			}
+			componentTypes = append(componentTypes, componentType)
+		}
+		return "(" + strings.Join(componentTypes, ",") + ")", nil
+	default:
+		return "", fmt.Errorf("unsupported ABI type: %s", tag)
+	}
+}
@@ -1,8 +1,15 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/types"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -37,6 +44,19 @@ func TestParseReproducerFile_Errors(t *testing.T) {
 	assert.Len(t, calls, 0)
 }
 
+func TestParseReproducer_Reader(t *testing.T) {
+	data, err := os.ReadFile("testdata/valid_reproducer.json")
+	require.NoError(t, err)
+
+	calls, err := ParseReproducer(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "withdraw", calls[1].FunctionName)
+
+	_, err = ParseReproducer(strings.NewReader("not json"))
+	assert.Error(t, err)
+}
+
 func TestParseParameter_BasicTypes(t *testing.T) {
 	// Test uint
 	param, err := parseParameter(map[string]interface{}{
@@ -64,3 +84,295 @@ func TestParseParameter_BasicTypes(t *testing.T) {
 	assert.Equal(t, "bool", param.Type)
 	assert.Equal(t, "true", param.Value)
 }
+
+func TestParseParameter_BareContents(t *testing.T) {
+	// Echidna writes single-field values bare, older reproducers wrap them in an array
+	for _, contents := range []interface{}{
+		"0x1234567890123456789012345678901234567890",
+		[]interface{}{"0x1234567890123456789012345678901234567890"},
+	} {
+		param, err := parseParameter(map[string]interface{}{"tag": "AbiAddress", "contents": contents})
+		require.NoError(t, err)
+		assert.Equal(t, types.ParsedParam{Type: "address", Value: "0x1234567890123456789012345678901234567890"}, param)
+	}
+
+	for _, contents := range []interface{}{"hi there", []interface{}{"hi there"}} {
+		param, err := parseParameter(map[string]interface{}{"tag": "AbiString", "contents": contents})
+		require.NoError(t, err)
+		assert.Equal(t, types.ParsedParam{Type: "string", Value: `"hi there"`}, param)
+	}
+
+	// Other tags keep requiring their array form
+	_, err := parseParameter(map[string]interface{}{"tag": "AbiUInt", "contents": "1000"})
+	assert.ErrorContains(t, err, "not an array")
+}
+
+func TestParseParameter_BytesDynamic(t *testing.T) {
+	// AbiBytesDynamic carries its bytes as a bare string, hex or raw
+	for _, value := range []string{"0xdeadbeef", "raw bytes"} {
+		param, err := parseParameter(map[string]interface{}{"tag": "AbiBytesDynamic", "contents": value})
+		require.NoError(t, err)
+		assert.Equal(t, types.ParsedParam{Type: "bytes", Value: value}, param)
+	}
+
+	_, err := parseParameter(map[string]interface{}{"tag": "AbiBytesDynamic", "contents": []interface{}{"0xdeadbeef"}})
+	assert.ErrorContains(t, err, "dynamic bytes value is not a string")
+}
+
+func TestParseReproducerFile_Medusa(t *testing.T) {
+	calls, err := ParseReproducerFile("testdata/medusa_sequence.json")
+	require.NoError(t, err)
+	assert.Len(t, calls, 2)
+
+	assert.Equal(t, "deposit", calls[0].FunctionName)
+	require.Len(t, calls[0].Parameters, 1)
+	assert.Equal(t, "uint256", calls[0].Parameters[0].Type)
+	assert.Equal(t, "3625", calls[0].Parameters[0].Value)
+	assert.Equal(t, "0x0000000000000000000000000000000000010000", calls[0].Src)
+	assert.False(t, calls[0].HasDelay)
+
+	assert.Equal(t, "withdraw", calls[1].FunctionName)
+	require.Len(t, calls[1].Parameters, 2)
+	assert.Equal(t, "address", calls[1].Parameters[0].Type)
+	assert.Equal(t, "true", calls[1].Parameters[1].Value)
+	assert.True(t, calls[1].HasDelay)
+	assert.Equal(t, "30", calls[1].DelayValue)
+	assert.True(t, calls[1].HasBlockDelay)
+	assert.Equal(t, "12", calls[1].BlockDelayValue)
+}
+
+func TestParseData_MedusaKeyedTuple(t *testing.T) {
+	cfg := []types.ParsedParam{{Type: "(uint256,address)", Elements: []types.ParsedParam{
+		{Type: "uint256", Value: "7"},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+	}}}
+	calldata, err := abi.EncodeCall("setCfg((uint256,address))", cfg)
+	require.NoError(t, err)
+
+	sequence := `[{"call": {"from": "0x0000000000000000000000000000000000010000", "to": "0xa647ff3c36cfab592509e13860ab8c4f28781a66",
+		"nonce": 0, "value": "0x0", "gasLimit": 12500000, "gasPrice": "0x1", "data": "0x` + hex.EncodeToString(calldata) + `",
+		"dataAbiValues": {"methodSignature": "setCfg((uint256,address))",
+			"inputValues": [{"limit": "7", "owner": "0x000000000000000000000000000000000000dead"}]}},
+		"blockNumberDelay": 0, "blockTimestampDelay": 5}]`
+
+	calls, err := ParseData([]byte(sequence))
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+
+	// The call is replayed from its calldata, which still decodes to the tuple
+	assert.Equal(t, types.CallKindRaw, calls[0].Kind)
+	assert.Equal(t, "0x"+hex.EncodeToString(calldata), calls[0].Calldata)
+	assert.Equal(t, "5", calls[0].DelayValue)
+
+	params, err := abi.DecodeArguments([]string{"(uint256,address)"}, calldata[4:])
+	require.NoError(t, err)
+	assert.Equal(t, cfg, params)
+}
+
+func TestParseData_MedusaLargeIntegers(t *testing.T) {
+	sequence := `[{"call": {"from": "0x0000000000000000000000000000000000010000", "to": "0xa647ff3c36cfab592509e13860ab8c4f28781a66",
+		"nonce": 0, "value": 9007199254740993, "gasLimit": 12500000, "gasPrice": 1, "data": "0x",
+		"dataAbiValues": {"methodSignature": "set(uint256,int256)",
+			"inputValues": [115792089237316195423570985008687907853269984665640564039457584007913129639935, -9007199254740993]}},
+		"blockNumberDelay": 0, "blockTimestampDelay": 0}]`
+
+	calls, err := ParseData([]byte(sequence))
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+
+	// JSON numbers above 2^53 keep their precision
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "uint256", Value: "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{Type: "int256", Value: "-9007199254740993"},
+	}, calls[0].Parameters)
+	assert.Equal(t, "0x20000000000001", calls[0].Value)
+}
+
+func TestParseReproducerFile_Foundry(t *testing.T) {
+	calls, err := ParseReproducerFile("testdata/foundry_sequence.txt")
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+
+	deposit := calls[0]
+	assert.Equal(t, "deposit", deposit.FunctionName)
+	assert.Equal(t, "0x00000000000000000000000000000000000014aD", deposit.Src)
+	assert.Equal(t, "0xF62849F9A0B5Bf2913b396098F7c7019b51A820a", deposit.Dst)
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "uint256", Value: "1000000000000000000"},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+	}, deposit.Parameters)
+	assert.False(t, deposit.HasDelay)
+
+	setPath := calls[1]
+	assert.True(t, setPath.HasDelay)
+	assert.Equal(t, "3600", setPath.DelayValue)
+	assert.True(t, setPath.HasBlockDelay)
+	assert.Equal(t, "12", setPath.BlockDelayValue)
+	assert.Equal(t, types.ParsedParam{Type: "uint256[]", Elements: []types.ParsedParam{
+		{Type: "uint256", Value: "1"},
+		{Type: "uint256", Value: "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+	}}, setPath.Parameters[0])
+	assert.Equal(t, types.ParsedParam{Type: "(string,bool)", Elements: []types.ParsedParam{
+		{Type: "string", Value: `"a, (b]"`},
+		{Type: "bool", Value: "true"},
+	}}, setPath.Parameters[1])
+
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "int256", Value: "-5"},
+		{Type: "bytes", Value: "0xdeadbeef"},
+	}, calls[2].Parameters)
+
+}
+
+func TestParseReproducer_FoundryPersistedFailure(t *testing.T) {
+	deposit := "0x6e553f65" +
+		"00000000000000000000000000000000000000000000000000000000000003e8" +
+		"000000000000000000000000000000000000000000000000000000000000dead"
+	data := `{"call_sequence": [
+		{"sender": "0x00000000000000000000000000000000000014aD", "call_details": {"target": "0xF62849F9A0B5Bf2913b396098F7c7019b51A820a", "calldata": "` + deposit + `"}},
+		{"warp": "0x3c", "roll": 2, "sender": "0x00000000000000000000000000000000000014aD", "call_details": {"target": "0xF62849F9A0B5Bf2913b396098F7c7019b51A820a", "calldata": "0xcafebabe"}},
+		{"warp": 9007199254740993, "sender": "0x00000000000000000000000000000000000014aD", "call_details": {"target": "0xF62849F9A0B5Bf2913b396098F7c7019b51A820a", "calldata": "0xcafebabe"}}
+	]}`
+
+	calls, err := ParseReproducer(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+	assert.Equal(t, types.CallKindRaw, calls[0].Kind)
+	assert.Equal(t, deposit, calls[0].Calldata)
+	assert.Equal(t, "60", calls[1].DelayValue)
+	assert.Equal(t, "2", calls[1].BlockDelayValue)
+	assert.Equal(t, "9007199254740993", calls[2].DelayValue)
+	assert.Equal(t, 3, CountCalls(calls, types.CallKindRaw))
+
+	// Calls matching the ABI are decoded, the rest stay raw
+	contractABI, err := abi.Parse([]byte(`[{"type":"function","name":"deposit","inputs":[{"name":"assets","type":"uint256"},{"name":"receiver","type":"address"}]}]`))
+	require.NoError(t, err)
+	decodedCalls, decoded := DecodeRawCalls(calls, contractABI)
+	assert.Equal(t, 1, decoded)
+	assert.Equal(t, types.CallKindFunction, decodedCalls[0].Kind)
+	assert.Equal(t, "deposit", decodedCalls[0].FunctionName)
+	assert.Equal(t, []types.ParsedParam{
+		{Type: "uint256", Value: "1000"},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+	}, decodedCalls[0].Parameters)
+	assert.Equal(t, types.CallKindRaw, decodedCalls[1].Kind)
+	assert.Equal(t, types.CallKindRaw, calls[0].Kind, "the input calls are not modified")
+}
+
+func TestParseTransactions_CalldataCreateAndUnsupported(t *testing.T) {
+	transactions := types.EchidnaReproducer{
+		{Call: types.Call{Tag: "SolCalldata", Contents: "0xcafebabe"}, Delay: []string{"0x0", "0x0"}, Src: "0x10000", Value: "0x0"},
+		{Call: types.Call{Tag: "SolCalldata", Contents: "\u00ca\u00fe"}, Delay: []string{"0x0", "0x0"}, Src: "0x10000", Value: "0x0"},
+		{Call: types.Call{Tag: "SolCreate", Contents: "0x6080"}, Delay: []string{"0x0", "0x0"}, Src: "0x10000", Value: "0x0"},
+		{Call: types.Call{Tag: "SolSelfdestruct"}, Delay: []string{"0x1", "0x0"}, Src: "0x10000", Value: "0x0"},
+	}
+
+	calls, err := parseTransactions(transactions)
+	require.NoError(t, err)
+	require.Len(t, calls, 4)
+
+	assert.Equal(t, types.CallKindRaw, calls[0].Kind)
+	assert.Equal(t, "0xcafebabe", calls[0].Calldata)
+	assert.Equal(t, "0xcafe", calls[1].Calldata)
+
+	assert.Equal(t, types.CallKindCreate, calls[2].Kind)
+	assert.Equal(t, "0x6080", calls[2].Calldata)
+
+	assert.Equal(t, types.CallKindUnsupported, calls[3].Kind)
+	assert.Contains(t, calls[3].Note, "SolSelfdestruct")
+	assert.True(t, calls[3].HasDelay, "delays of skipped transactions are kept")
+}
+
+func TestParseParameter_NestedTypes(t *testing.T) {
+	// Dynamic array of uint256
+	param, err := parseParameter(map[string]interface{}{
+		"tag": "AbiArrayDynamic",
+		"contents": []interface{}{
+			map[string]interface{}{"tag": "AbiUIntType", "contents": 256.0},
+			[]interface{}{
+				map[string]interface{}{"tag": "AbiUInt", "contents": []interface{}{256.0, "1"}},
+				map[string]interface{}{"tag": "AbiUInt", "contents": []interface{}{256.0, "2"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "uint256[]", param.Type)
+	require.Len(t, param.Elements, 2)
+	assert.Equal(t, "2", param.Elements[1].Value)
+
+	// Empty dynamic array keeps its element type
+	param, err = parseParameter(map[string]interface{}{
+		"tag": "AbiArrayDynamic",
+		"contents": []interface{}{
+			map[string]interface{}{"tag": "AbiArrayType", "contents": []interface{}{2.0, map[string]interface{}{"tag": "AbiAddressType"}}},
+			[]interface{}{},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "address[2][]", param.Type)
+	assert.Empty(t, param.Elements)
+
+	// Fixed array with mismatched length
+	_, err = parseParameter(map[string]interface{}{
+		"tag": "AbiArray",
+		"contents": []interface{}{
+			3.0,
+			map[string]interface{}{"tag": "AbiBoolType"},
+			[]interface{}{map[string]interface{}{"tag": "AbiBool", "contents": true}},
+		},
+	})
+	assert.Error(t, err)
+
+	// Tuple
+	param, err = parseParameter(map[string]interface{}{
+		"tag": "AbiTuple",
+		"contents": []interface{}{
+			map[string]interface{}{"tag": "AbiAddress", "contents": []interface{}{"0x1234567890123456789012345678901234567890"}},
+			map[string]interface{}{"tag": "AbiUInt", "contents": []interface{}{8.0, "7"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "(address,uint8)", param.Type)
+	require.Len(t, param.Elements, 2)
+	assert.Equal(t, "7", param.Elements[1].Value)
+}
+
+func TestParseDelay(t *testing.T) {
+	// Large delays must not be truncated
+	timeDelay, blockDelay, err := parseDelay([]string{
+		"0x0000000000000000000000000000000000000000000000000000000100000000",
+		"0x000000000000000000000000000000000000000000000000000000000000ea60",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "4294967296", timeDelay.String())
+	assert.Equal(t, "60000", blockDelay.String())
+
+	// Block delay without time delay
+	call := &types.ParsedCall{}
+	timeDelay, blockDelay, err = parseDelay([]string{"0x0", "0x5"})
+	require.NoError(t, err)
+	applyDelay(call, timeDelay, blockDelay)
+	assert.False(t, call.HasDelay)
+	assert.True(t, call.HasBlockDelay)
+	assert.Equal(t, "5", call.BlockDelayValue)
+
+	// Malformed delays are reported
+	_, _, err = parseDelay([]string{"0xnothex", "0x0"})
+	assert.Error(t, err)
+}
+
+func TestDecodeCreations(t *testing.T) {
+	artifacts, err := abi.LoadArtifacts("../abi/testdata/out")
+	require.NoError(t, err)
+
+	// Vault bytecode followed by its ABI encoded constructor argument
+	vault := types.ParsedCall{Kind: types.CallKindCreate, Parameters: []types.ParsedParam{},
+		Calldata: "0x6080604052" + "000000000000000000000000000000000000000000000000000000000000dead"}
+	unknown := types.ParsedCall{Kind: types.CallKindCreate, Parameters: []types.ParsedParam{}, Calldata: "0x60016002"}
+
+	calls, matched := DecodeCreations([]types.ParsedCall{vault, unknown}, artifacts)
+	assert.Equal(t, 1, matched)
+	assert.Equal(t, "Vault", calls[0].ContractName)
+	assert.Equal(t, []types.ParsedParam{{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"}}, calls[0].Parameters)
+	assert.Empty(t, calls[1].ContractName)
+}
@@ -0,0 +1,226 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+var (
+	// failedProperty matches the line Echidna prints for a broken property, e.g. "echidna_solvency: failed!💥"
+	failedProperty = regexp.MustCompile(`^\s*([^\s:][^:]*?)\s*:\s*failed!`)
+	argumentToken  = regexp.MustCompile(`-?0x[0-9a-fA-F]+|-?[0-9]+|[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// Campaign holds the properties an Echidna campaign broke, with their call sequences
+type Campaign struct {
+	Properties []FailedProperty
+}
+
+// FailedProperty is a broken property and the call sequence that breaks it
+type FailedProperty struct {
+	Name  string
+	Calls []LoggedCall
+}
+
+// LoggedCall is a call of a sequence printed by Echidna
+type LoggedCall struct {
+	Function  string
+	Arguments string // Arguments as printed, without the enclosing parentheses
+}
+
+// echidnaJSONOutput is the output of echidna --format json
+type echidnaJSONOutput struct {
+	Tests []struct {
+		Name         string `json:"name"`
+		Status       string `json:"status"`
+		Transactions []struct {
+			Function  string   `json:"function"`
+			Arguments []string `json:"arguments"`
+		} `json:"transactions"`
+	} `json:"tests"`
+}
+
+// LoadCampaign reads an Echidna campaign log in text or JSON (--format json) form
+func LoadCampaign(path string) (*Campaign, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read campaign log: %w", err)
+	}
+
+	campaign, err := ParseCampaign(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse campaign log %s: %w", path, err)
+	}
+	return campaign, nil
+}
+
+// ParseCampaign parses an Echidna campaign log in text or JSON form
+func ParseCampaign(data []byte) (*Campaign, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseCampaignJSON(trimmed)
+	}
+	return parseCampaignText(string(data)), nil
+}
+
+// parseCampaignJSON reads the failed tests of echidna --format json
+func parseCampaignJSON(data []byte) (*Campaign, error) {
+	var output echidnaJSONOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	campaign := &Campaign{}
+	for _, test := range output.Tests {
+		if len(test.Transactions) == 0 || test.Status == "passed" || test.Status == "fuzzing" {
+			continue
+		}
+		property := FailedProperty{Name: test.Name}
+		for _, tx := range test.Transactions {
+			property.Calls = append(property.Calls, LoggedCall{
+				Function:  tx.Function,
+				Arguments: strings.Join(tx.Arguments, ","),
+			})
+		}
+		campaign.Properties = append(campaign.Properties, property)
+	}
+	return campaign, nil
+}
+
+// parseCampaignText reads the failed properties of Echidna's text output:
+//
+//	echidna_solvency: failed!💥
+//	  Call sequence:
+//	    Tester.deposit(1) from: 0x0000000000000000000000000000000000010000 Time delay: 5 seconds
+//	    *wait* Time delay: 100 seconds Block delay: 2
+func parseCampaignText(text string) *Campaign {
+	campaign := &Campaign{}
+	var current *FailedProperty
+	inSequence := false
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if match := failedProperty.FindStringSubmatch(line); match != nil {
+			campaign.Properties = append(campaign.Properties, FailedProperty{Name: match[1]})
+			current = &campaign.Properties[len(campaign.Properties)-1]
+			inSequence = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "Call sequence"):
+			inSequence = true
+		case trimmed == "":
+			// The sequence ends at the first blank line after it started
+			if inSequence && len(current.Calls) > 0 {
+				current, inSequence = nil, false
+			}
+		case inSequence && !strings.HasPrefix(trimmed, "*wait*"):
+			if call, ok := parseLoggedCall(trimmed); ok {
+				current.Calls = append(current.Calls, call)
+			}
+		}
+	}
+	return campaign
+}
+
+// parseLoggedCall parses "Contract.function(arguments) from: ..." into a call
+func parseLoggedCall(line string) (LoggedCall, bool) {
+	if from := strings.Index(line, " from: "); from >= 0 {
+		line = line[:from]
+	}
+	start, end := strings.IndexByte(line, '('), strings.LastIndexByte(line, ')')
+	if start <= 0 || end < start {
+		return LoggedCall{}, false
+	}
+
+	function := line[:start]
+	if dot := strings.LastIndexByte(function, '.'); dot >= 0 {
+		function = function[dot+1:]
+	}
+	return LoggedCall{Function: function, Arguments: line[start+1 : end]}, true
+}
+
+// PropertyOf returns the property whose logged sequence calls the same functions as calls.
+// When several do, the one whose arguments are also equal is preferred.
+func (c *Campaign) PropertyOf(calls []types.ParsedCall) string {
+	var functions, arguments []string
+	for _, call := range calls {
+		if call.FunctionName == "" {
+			continue
+		}
+		functions = append(functions, call.FunctionName)
+		arguments = append(arguments, argumentKey(strings.Join(paramValues(call.Parameters), ",")))
+	}
+	if len(functions) == 0 {
+		return ""
+	}
+
+	candidate := ""
+	for _, property := range c.Properties {
+		if len(property.Calls) != len(functions) {
+			continue
+		}
+
+		sameFunctions, sameArguments := true, true
+		for i, call := range property.Calls {
+			if call.Function != functions[i] {
+				sameFunctions = false
+				break
+			}
+			if argumentKey(call.Arguments) != arguments[i] {
+				sameArguments = false
+			}
+		}
+		if !sameFunctions {
+			continue
+		}
+		if sameArguments {
+			return property.Name
+		}
+		if candidate == "" {
+			candidate = property.Name
+		}
+	}
+	return candidate
+}
+
+// paramValues flattens the values of parameters, including array and tuple elements
+func paramValues(params []types.ParsedParam) []string {
+	var values []string
+	for _, param := range params {
+		if len(param.Elements) > 0 {
+			values = append(values, paramValues(param.Elements)...)
+			continue
+		}
+		values = append(values, param.Value)
+	}
+	return values
+}
+
+// argumentKey reduces printed arguments to comparable tokens: numbers in decimal
+// (so 0x10 and 16 are equal) and words in lowercase, ignoring punctuation
+func argumentKey(arguments string) string {
+	tokens := argumentToken.FindAllString(arguments, -1)
+	for i, token := range tokens {
+		digits, base := token, 10
+		if strings.Contains(token, "0x") {
+			digits, base = strings.Replace(token, "0x", "", 1), 16
+		}
+		if n, ok := new(big.Int).SetString(digits, base); ok {
+			tokens[i] = n.String()
+		} else {
+			tokens[i] = strings.ToLower(token)
+		}
+	}
+	return strings.Join(tokens, ",")
+}
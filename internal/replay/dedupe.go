@@ -0,0 +1,260 @@
+package replay
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+	"github.com/Enigma-Dark/runes/internal/utils"
+)
+
+// DuplicateSet lists the files whose call sequences were collapsed into one replay group
+type DuplicateSet struct {
+	Kept       string   // File of the group that was kept
+	Merged     []string // Files whose groups were dropped in its favor
+	Similarity float64  // Lowest similarity of a merged sequence to the kept one (1 for identical)
+}
+
+// Canonicalize returns a copy of calls in a canonical form, so reproducers that replay the
+// same transactions compare equal: numbers are written in decimal (msg.value in hex),
+// addresses and hex data in lowercase, pure delays are folded into the delay of the next
+// call and gas settings, which the generated tests do not replay, are cleared.
+func Canonicalize(calls []types.ParsedCall) []types.ParsedCall {
+	result := make([]types.ParsedCall, 0, len(calls))
+	pendingDelay, pendingBlocks := new(big.Int), new(big.Int)
+
+	for _, call := range calls {
+		call.Src = canonicalAddress(call.Src)
+		call.Dst = canonicalAddress(call.Dst)
+		call.Value = canonicalValue(call.Value)
+		call.Calldata = strings.ToLower(call.Calldata)
+		call.Gas, call.GasPrice = 0, ""
+		call.Parameters = canonicalParams(call.Parameters)
+
+		if call.HasDelay {
+			pendingDelay.Add(pendingDelay, parseQuantity(call.DelayValue))
+		}
+		if call.HasBlockDelay {
+			pendingBlocks.Add(pendingBlocks, parseQuantity(call.BlockDelayValue))
+		}
+
+		// A pure delay only moves time for the next call
+		if call.Kind == types.CallKindFunction && call.FunctionName == "" {
+			continue
+		}
+
+		setDelays(&call, pendingDelay, pendingBlocks)
+		pendingDelay, pendingBlocks = new(big.Int), new(big.Int)
+		result = append(result, call)
+	}
+
+	// Trailing delays are kept as a single pure delay
+	if pendingDelay.Sign() > 0 || pendingBlocks.Sign() > 0 {
+		var delay types.ParsedCall
+		setDelays(&delay, pendingDelay, pendingBlocks)
+		result = append(result, delay)
+	}
+	return result
+}
+
+// Fingerprint identifies a call sequence by its canonical form
+func Fingerprint(calls []types.ParsedCall) string {
+	// ParsedCall only holds strings, numbers and booleans, which always marshal
+	data, _ := json.Marshal(Canonicalize(calls))
+	return string(data)
+}
+
+// Similarity compares two call sequences in canonical form. Sequences that call different
+// functions, with different senders, targets or argument types, have similarity 0.
+// Otherwise it is the fraction of argument values that are equal, also counting the
+// msg.values, calldata and delays that either sequence sets, so 1 means the sequences
+// are identical.
+func Similarity(a, b []types.ParsedCall) float64 {
+	a, b = Canonicalize(a), Canonicalize(b)
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var equal, total int
+	for i := range a {
+		x, y := a[i], b[i]
+		if x.Kind != y.Kind || x.FunctionName != y.FunctionName || x.ContractName != y.ContractName ||
+			x.Src != y.Src || x.Dst != y.Dst || x.Note != y.Note {
+			return 0
+		}
+
+		var values [][2]string
+		if !collectParams(x.Parameters, y.Parameters, &values) {
+			return 0
+		}
+
+		// Transaction settings only count when one of the calls uses them
+		for _, pair := range [][2]string{
+			{x.Value, y.Value},
+			{x.Calldata, y.Calldata},
+			{x.DelayValue, y.DelayValue},
+			{x.BlockDelayValue, y.BlockDelayValue},
+		} {
+			if unset(pair[0]) && unset(pair[1]) {
+				continue
+			}
+			values = append(values, pair)
+		}
+
+		for _, pair := range values {
+			total++
+			if pair[0] == pair[1] {
+				equal++
+			}
+		}
+	}
+
+	if total == 0 {
+		return 1
+	}
+	return float64(equal) / float64(total)
+}
+
+// Dedupe collapses replay groups that break the same property and whose call sequences
+// are at least threshold similar (1 keeps only exact duplicates). The first group of each
+// set of duplicates is kept, so the order of the remaining groups is preserved.
+func Dedupe(groups []types.ReplayGroup, threshold float64) ([]types.ReplayGroup, []DuplicateSet) {
+	var kept []types.ReplayGroup
+	var sets []DuplicateSet
+	setOf := make(map[int]int) // Index in kept to index in sets
+	fingerprints := make(map[string]int)
+
+	for _, group := range groups {
+		// Sequences breaking different properties check different things, so both are kept
+		fingerprint := group.Property + "\n" + Fingerprint(group.Calls)
+		match, similarity := -1, 0.0
+		if index, ok := fingerprints[fingerprint]; ok {
+			match, similarity = index, 1
+		} else if threshold < 1 {
+			for i := range kept {
+				if kept[i].Property != group.Property {
+					continue
+				}
+				if s := Similarity(kept[i].Calls, group.Calls); s >= threshold && s > similarity {
+					match, similarity = i, s
+				}
+			}
+		}
+
+		if match < 0 {
+			fingerprints[fingerprint] = len(kept)
+			kept = append(kept, group)
+			continue
+		}
+
+		index, ok := setOf[match]
+		if !ok {
+			index = len(sets)
+			setOf[match] = index
+			sets = append(sets, DuplicateSet{Kept: kept[match].FileName, Similarity: 1})
+		}
+		sets[index].Merged = append(sets[index].Merged, group.FileName)
+		if similarity < sets[index].Similarity {
+			sets[index].Similarity = similarity
+		}
+	}
+	return kept, sets
+}
+
+// collectParams pairs up the leaf values of two parameter lists, and reports whether
+// they have the same types and shape
+func collectParams(a, b []types.ParsedParam, values *[][2]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type {
+			return false
+		}
+		if len(a[i].Elements) > 0 || len(b[i].Elements) > 0 {
+			if !collectParams(a[i].Elements, b[i].Elements, values) {
+				return false
+			}
+			continue
+		}
+		*values = append(*values, [2]string{a[i].Value, b[i].Value})
+	}
+	return true
+}
+
+// unset reports whether a canonical transaction setting has its default value
+func unset(value string) bool {
+	return value == "" || value == "0x0"
+}
+
+// canonicalParams normalizes the values of integer, address, bool and bytes parameters
+func canonicalParams(params []types.ParsedParam) []types.ParsedParam {
+	if params == nil {
+		return nil
+	}
+	result := make([]types.ParsedParam, len(params))
+	for i, param := range params {
+		param.Elements = canonicalParams(param.Elements)
+		switch {
+		case strings.HasPrefix(param.Type, "uint") || strings.HasPrefix(param.Type, "int"):
+			if n, ok := new(big.Int).SetString(param.Value, 0); ok {
+				param.Value = n.String()
+			}
+		case param.Type == "address":
+			param.Value = canonicalAddress(param.Value)
+		case param.Type == "bool":
+			param.Value = strings.ToLower(param.Value)
+		case strings.HasPrefix(param.Type, "bytes") && strings.HasPrefix(param.Value, "0x"):
+			param.Value = strings.ToLower(param.Value)
+		}
+		result[i] = param
+	}
+	return result
+}
+
+// canonicalAddress normalizes an address, leaving values that are not addresses unchanged
+func canonicalAddress(address string) string {
+	if address == "" {
+		return ""
+	}
+	normalized, err := utils.NormalizeAddress(address)
+	if err != nil {
+		return address
+	}
+	return normalized
+}
+
+// canonicalValue normalizes a hex msg.value
+func canonicalValue(value string) string {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		if digits == "" {
+			return "0x0"
+		}
+		return value
+	}
+	return "0x" + n.Text(16)
+}
+
+// parseQuantity parses a decimal or 0x-prefixed delay, treating invalid values as zero
+func parseQuantity(value string) *big.Int {
+	n, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+// setDelays replaces the delays of a call
+func setDelays(call *types.ParsedCall, timeDelay, blockDelay *big.Int) {
+	call.HasDelay, call.DelayValue = timeDelay.Sign() > 0, ""
+	if call.HasDelay {
+		call.DelayValue = timeDelay.String()
+	}
+	call.HasBlockDelay, call.BlockDelayValue = blockDelay.Sign() > 0, ""
+	if call.HasBlockDelay {
+		call.BlockDelayValue = blockDelay.String()
+	}
+}
@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// deposit builds a call to deposit(uint256,address) from 0x10000
+func deposit(amount, receiver string) types.ParsedCall {
+	return types.ParsedCall{
+		FunctionName: "deposit",
+		Parameters: []types.ParsedParam{
+			{Type: "uint256", Value: amount},
+			{Type: "address", Value: receiver},
+		},
+		Src:   "0x10000",
+		Dst:   "0x00000000000000000000000000000000ffffffff",
+		Value: "0x0",
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	delay := types.ParsedCall{HasDelay: true, DelayValue: "10", Src: "0x10000"}
+	call := deposit("0x10", "0x000000000000000000000000000000000000ABCD")
+	call.HasDelay, call.DelayValue = true, "5"
+	call.Gas, call.GasPrice = 12500000, "0x0"
+	call.Value = "0x00"
+
+	calls := Canonicalize([]types.ParsedCall{delay, call, delay})
+
+	assert.Equal(t, []types.ParsedCall{
+		{
+			FunctionName: "deposit",
+			Parameters: []types.ParsedParam{
+				{Type: "uint256", Value: "16"},
+				{Type: "address", Value: "0x000000000000000000000000000000000000abcd"},
+			},
+			Src:        "0x0000000000000000000000000000000000010000",
+			Dst:        "0x00000000000000000000000000000000ffffffff",
+			Value:      "0x0",
+			HasDelay:   true,
+			DelayValue: "15",
+		},
+		{HasDelay: true, DelayValue: "10"},
+	}, calls)
+}
+
+func TestSimilarity(t *testing.T) {
+	base := []types.ParsedCall{deposit("1", "0xabcd"), deposit("2", "0xabcd")}
+
+	assert.Equal(t, 1.0, Similarity(base, []types.ParsedCall{deposit("0x1", "0xABCD"), deposit("2", "0xabcd")}))
+	assert.Equal(t, 0.75, Similarity(base, []types.ParsedCall{deposit("1", "0xabcd"), deposit("3", "0xabcd")}))
+
+	// Different functions, senders or lengths are never similar
+	withdraw := deposit("1", "0xabcd")
+	withdraw.FunctionName = "withdraw"
+	assert.Equal(t, 0.0, Similarity(base, []types.ParsedCall{deposit("1", "0xabcd"), withdraw}))
+
+	otherSender := deposit("2", "0xabcd")
+	otherSender.Src = "0x20000"
+	assert.Equal(t, 0.0, Similarity(base, []types.ParsedCall{deposit("1", "0xabcd"), otherSender}))
+	assert.Equal(t, 0.0, Similarity(base, base[:1]))
+}
+
+func TestDedupe(t *testing.T) {
+	group := func(file string, calls ...types.ParsedCall) types.ReplayGroup {
+		return types.ReplayGroup{FileName: file, Calls: calls}
+	}
+	groups := []types.ReplayGroup{
+		group("a.txt", deposit("1", "0xabcd"), deposit("2", "0xabcd")),
+		group("b.txt", deposit("100", "0x1234")),
+		group("c.txt", deposit("0x1", "0xABCD"), deposit("0x2", "0xabcd")),
+		group("d.txt", deposit("1", "0xabcd"), deposit("5", "0xabcd")),
+	}
+
+	kept, sets := Dedupe(groups, 1)
+	assert.Equal(t, []string{"a.txt", "b.txt", "d.txt"}, fileNames(kept))
+	assert.Equal(t, []DuplicateSet{{Kept: "a.txt", Merged: []string{"c.txt"}, Similarity: 1}}, sets)
+
+	kept, sets = Dedupe(groups, 0.7)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, fileNames(kept))
+	assert.Equal(t, []DuplicateSet{{Kept: "a.txt", Merged: []string{"c.txt", "d.txt"}, Similarity: 0.75}}, sets)
+
+	// Sequences breaking different properties are never collapsed
+	groups[2].Property = "invariant_b"
+	groups[3].Property = "invariant_b"
+	kept, sets = Dedupe(groups, 0.7)
+	assert.Equal(t, []string{"a.txt", "b.txt", "c.txt"}, fileNames(kept))
+	assert.Equal(t, []DuplicateSet{{Kept: "c.txt", Merged: []string{"d.txt"}, Similarity: 0.75}}, sets)
+}
+
+// fileNames returns the files of the groups in order
+func fileNames(groups []types.ReplayGroup) []string {
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.FileName)
+	}
+	return names
+}
@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+var (
+	// propertyName finds a property function name, as used by Echidna, Medusa and Foundry
+	propertyName = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])((?:echidna|invariant|property|prop)_[a-z0-9_]*[a-z0-9])`)
+	invalidIdent = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// Namer assigns unique, valid Solidity test function names to the replay groups of one
+// output contract. Tests replaying a broken property are named after it and numbered,
+// e.g. test_replay_invariant_solvency_1; others are named after their last function call,
+// with a _2, _3, ... suffix when the name is already taken.
+type Namer struct {
+	number string // Prefix number taken from a ReplayTest_N output file, if any
+	used   map[string]bool
+	counts map[string]int
+}
+
+// NewNamer creates a namer. number is inserted after test_replay_ when not empty.
+func NewNamer(number string) *Namer {
+	return &Namer{
+		number: number,
+		used:   make(map[string]bool),
+		counts: make(map[string]int),
+	}
+}
+
+// Reserve marks a name as taken, e.g. by a test that is already in the contract
+func (n *Namer) Reserve(name string) {
+	n.used[name] = true
+}
+
+// Name returns a new unique test name for a replay of calls breaking property ("" when unknown)
+func (n *Namer) Name(property string, calls []types.ParsedCall) string {
+	prefix := "test_replay_"
+	if n.number != "" {
+		prefix = fmt.Sprintf("test_replay_%s_", sanitizeIdentifier(n.number))
+	}
+
+	if property != "" {
+		base := prefix + sanitizeIdentifier(property)
+		for {
+			n.counts[base]++
+			name := fmt.Sprintf("%s_%d", base, n.counts[base])
+			if !n.used[name] {
+				n.used[name] = true
+				return name
+			}
+		}
+	}
+
+	base, _ := GenerateTestFunctionName("", "", calls)
+	base = prefix + sanitizeIdentifier(strings.TrimPrefix(base, "test_replay_"))
+	name := base
+	for i := 2; n.used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	n.used[name] = true
+	return name
+}
+
+// InferProperty returns the name of the property broken by a reproducer: the property
+// function named in its file name (Foundry names persisted failures after the invariant),
+// or the failed property of the campaign log that replays the same call sequence.
+// It returns "" when the property is unknown.
+func InferProperty(path string, calls []types.ParsedCall, campaign *Campaign) string {
+	base := filepath.Base(path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if match := propertyName.FindStringSubmatch(base); match != nil {
+		return match[1]
+	}
+
+	if campaign != nil {
+		return campaign.PropertyOf(calls)
+	}
+	return ""
+}
+
+// sanitizeIdentifier turns text into a fragment of a Solidity identifier
+func sanitizeIdentifier(text string) string {
+	// Assertion failures are reported with their signature, e.g. check_balance(uint256)
+	if paren := strings.IndexByte(text, '('); paren > 0 {
+		text = text[:paren]
+	}
+	text = strings.Trim(invalidIdent.ReplaceAllString(text, "_"), "_")
+	if text == "" {
+		return "default"
+	}
+	return text
+}
@@ -0,0 +1,91 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+const campaignText = `[2024-05-01 10:00:00.00] Compiling .... Done!
+echidna_solvency: failed!💥
+  Call sequence:
+    Tester.deposit(1) from: 0x0000000000000000000000000000000000010000 Time delay: 5 seconds Block delay: 1
+    *wait* Time delay: 100 seconds Block delay: 2
+    Tester.withdraw(0x10) from: 0x0000000000000000000000000000000000010000
+
+Traces:
+call Tester::withdraw(16)
+
+echidna_no_debt: failed!💥
+  Call sequence:
+    Tester.deposit(1) from: 0x0000000000000000000000000000000000010000
+    Tester.withdraw(7) from: 0x0000000000000000000000000000000000020000
+
+echidna_total_supply: passing
+`
+
+func TestParseCampaign_Text(t *testing.T) {
+	campaign, err := ParseCampaign([]byte(campaignText))
+	require.NoError(t, err)
+
+	assert.Equal(t, []FailedProperty{
+		{Name: "echidna_solvency", Calls: []LoggedCall{{Function: "deposit", Arguments: "1"}, {Function: "withdraw", Arguments: "0x10"}}},
+		{Name: "echidna_no_debt", Calls: []LoggedCall{{Function: "deposit", Arguments: "1"}, {Function: "withdraw", Arguments: "7"}}},
+	}, campaign.Properties)
+}
+
+func TestParseCampaign_JSON(t *testing.T) {
+	campaign, err := ParseCampaign([]byte(`{"success": true, "tests": [
+		{"name": "echidna_total_supply", "status": "passed", "transactions": null},
+		{"name": "check_balance(uint256)", "status": "solved", "transactions": [
+			{"contract": "Tester", "function": "check_balance", "arguments": ["3"], "gas": 0, "gasprice": 0}
+		]}
+	]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []FailedProperty{
+		{Name: "check_balance(uint256)", Calls: []LoggedCall{{Function: "check_balance", Arguments: "3"}}},
+	}, campaign.Properties)
+}
+
+func TestNamer(t *testing.T) {
+	call := func(name, value string) types.ParsedCall {
+		return types.ParsedCall{FunctionName: name, Parameters: []types.ParsedParam{{Type: "uint256", Value: value}}}
+	}
+	campaign, err := ParseCampaign([]byte(campaignText))
+	require.NoError(t, err)
+
+	name := func(namer *Namer, path string, calls ...types.ParsedCall) string {
+		return namer.Name(InferProperty(path, calls, campaign), calls)
+	}
+	namer := NewNamer("")
+	namer.Reserve("test_replay_deposit")
+
+	// Properties are found in the campaign log by call sequence, preferring equal arguments
+	assert.Equal(t, "test_replay_echidna_solvency_1", name(namer, "a.txt", call("deposit", "1"), call("withdraw", "16")))
+	assert.Equal(t, "test_replay_echidna_no_debt_1", name(namer, "b.txt", call("deposit", "1"), call("withdraw", "7")))
+	assert.Equal(t, "test_replay_echidna_solvency_2", name(namer, "c.txt", call("deposit", "1"), call("withdraw", "99")))
+
+	// File names mentioning a property take precedence
+	assert.Equal(t, "test_replay_invariant_solvency_1", name(namer, "failures/Invariants/invariant_solvency", call("deposit", "1")))
+
+	// Unknown properties fall back to the last function, made unique
+	assert.Equal(t, "test_replay_deposit_2", name(namer, "d.txt", call("deposit", "1")))
+	assert.Equal(t, "test_replay_default", name(namer, "e.txt", types.ParsedCall{HasDelay: true, DelayValue: "5"}))
+
+	// Assertion failures are reported with the signature of the function
+	assert.Equal(t, "test_replay_check_balance_1", namer.Name("check_balance(uint256)", nil))
+
+	numbered := NewNamer("3")
+	assert.Equal(t, "test_replay_3_deposit", name(numbered, "a.txt", call("deposit", "1")))
+	assert.Equal(t, "test_replay_3_property_no_debt_1", name(numbered, "1714-property_no_debt.json"))
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	assert.Equal(t, "check_balance", sanitizeIdentifier("check_balance(uint256)"))
+	assert.Equal(t, "Vault_solvency", sanitizeIdentifier("Vault.solvency"))
+	assert.Equal(t, "default", sanitizeIdentifier("💥"))
+}
@@ -1,62 +1,226 @@
 package replay
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 
+	"github.com/Enigma-Dark/runes/internal/abi"
 	"github.com/Enigma-Dark/runes/internal/files"
 	"github.com/Enigma-Dark/runes/internal/logger"
 	"github.com/Enigma-Dark/runes/internal/parser"
 	"github.com/Enigma-Dark/runes/internal/types"
 )
 
-// ProcessFiles converts a list of replay files to ReplayGroups with detailed logging
-func ProcessFiles(replayFiles []files.FileInfo) ([]types.ReplayGroup, error) {
+// ProcessOptions controls how replay files are turned into replay groups
+type ProcessOptions struct {
+	Dedupe          bool                    // Collapse reproducers that replay the same call sequence
+	DedupeThreshold float64                 // Minimum similarity of collapsed sequences, 1 for exact duplicates only
+	Namer           *Namer                  // Names the test functions, a namer without number when nil
+	Campaign        *Campaign               // Campaign log used to find the property each reproducer breaks (optional)
+	Property        string                  // Property broken by every reproducer, instead of inferring it (optional)
+	Logger          *logger.ProcessorLogger // Collects the statistics of the run, a new logger when nil
+	Jobs            int                     // Files parsed in parallel, the number of CPUs when 0
+	Selection       Selection               // Sequences kept from each origin, all when zero
+}
+
+// ProcessFiles converts a list of replay files to ReplayGroups with detailed logging.
+// Files are parsed in parallel, but results are logged and returned in the order of
+// replayFiles. It stops with the context error when ctx is cancelled.
+func ProcessFiles(ctx context.Context, replayFiles []files.FileInfo, options ProcessOptions) ([]types.ReplayGroup, error) {
 	var allReplays []types.ReplayGroup
-	log := logger.NewProcessorLogger()
+	log := options.Logger
+	if log == nil {
+		log = logger.NewProcessorLogger()
+	}
 
-	fmt.Printf("Processing %d replay files...\n\n", len(replayFiles))
+	logger.Event(slog.LevelInfo, fmt.Sprintf("Processing %d replay files...\n", len(replayFiles)), "processing files", "files", len(replayFiles))
 
-	for _, file := range replayFiles {
+	results, err := parseFiles(ctx, replayFiles, options.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileErrors []error
+	for i, file := range replayFiles {
 		log.LogFileStart(file.Path)
 
-		calls, err := parser.ParseReproducerFile(file.Path)
+		calls, err := results[i].calls, results[i].err
+		if err == nil && len(calls) == 0 {
+			err = fmt.Errorf("no valid calls found")
+		}
 		if err != nil {
 			log.LogFileFailure(file.Path, err)
+			fileErrors = append(fileErrors, fmt.Errorf("%s: %w", filepath.Base(file.Path), err))
 			continue
 		}
 
-		if len(calls) == 0 {
-			log.LogFileFailure(file.Path, fmt.Errorf("no valid calls found"))
-			continue
+		// Coverage sequences are regression seeds that broke no property
+		property := ""
+		if file.Origin != files.OriginCoverage {
+			property = options.Property
+			if property == "" {
+				property = InferProperty(file.Path, calls, options.Campaign)
+			}
 		}
 
 		replayGroup := types.ReplayGroup{
-			TestName: "", // Will be set later when output file is known
+			TestName: "", // Set once duplicates are removed
 			Calls:    calls,
 			FileName: file.Path,
+			Property: property,
+			Origin:   file.Origin,
 		}
 
-		// Generate simple display name for logging only
 		_, lastFunction := GenerateTestFunctionName(file.Path, "", calls)
-		displayName := "test_replay"
-		if lastFunction != "" {
-			displayName = fmt.Sprintf("test_replay_%s", lastFunction)
+		allReplays = append(allReplays, replayGroup)
+		log.LogFileSuccess(file.Path, lastFunction, len(calls))
+
+		// Transactions that cannot be replayed are kept as comments, never dropped silently
+		if skipped := parser.CountCalls(calls, types.CallKindUnsupported); skipped > 0 {
+			log.LogFileWarning(file.Path, fmt.Sprintf("%d transactions cannot be replayed and are left as comments", skipped))
 		}
+	}
 
-		allReplays = append(allReplays, replayGroup)
-		log.LogFileSuccess(file.Path, displayName, lastFunction, len(calls))
+	if options.Dedupe {
+		var duplicates []DuplicateSet
+		allReplays, duplicates = Dedupe(allReplays, options.DedupeThreshold)
+		for _, set := range duplicates {
+			log.LogDuplicates(set.Kept, set.Merged, set.Similarity)
+		}
+	}
+
+	if !options.Selection.IsZero() {
+		selected := Select(allReplays, options.Selection)
+		logger.Event(slog.LevelInfo, fmt.Sprintf("Selected %d of %d sequences", len(selected), len(allReplays)),
+			"selected sequences", "selected", len(selected), "sequences", len(allReplays))
+		allReplays = selected
+	}
+
+	namer := options.Namer
+	if namer == nil {
+		namer = NewNamer("")
+	}
+	for i := range allReplays {
+		property := allReplays[i].Property
+		allReplays[i].TestName = namer.Name(property, allReplays[i].Calls)
+		logger.Debug("named test", "file", allReplays[i].FileName, "test", allReplays[i].TestName, "property", property)
+		_, lastFunction := GenerateTestFunctionName(allReplays[i].FileName, "", allReplays[i].Calls)
+		log.LogTest(allReplays[i].FileName, allReplays[i].TestName, lastFunction, len(allReplays[i].Calls))
 	}
 
 	// Print summary
 	log.LogProcessingSummary()
 
+	if len(allReplays) == 0 && len(fileErrors) < len(replayFiles) {
+		return nil, fmt.Errorf("none of the %d parsed sequences matches the selection", len(replayFiles)-len(fileErrors))
+	}
 	if len(allReplays) == 0 {
-		return nil, fmt.Errorf("no valid replay files found - all %d files failed to process", log.GetStats().FailureCount)
+		return nil, fmt.Errorf("no valid replay files found - all %d files failed to process: %w", log.GetStats().FailureCount, errors.Join(fileErrors...))
 	}
 
 	return allReplays, nil
 }
 
+// DecodeCalls decodes the raw calls of each group with the target ABI, matches deployments
+// against the artifacts, and warns about the calls that are still replayed from raw bytes
+func DecodeCalls(groups []types.ReplayGroup, contractABI *abi.ABI, artifacts *abi.ArtifactSet) {
+	for i := range groups {
+		calls, decoded := parser.DecodeRawCalls(groups[i].Calls, contractABI)
+		calls, matched := parser.DecodeCreations(calls, artifacts)
+		groups[i].Calls = calls
+
+		fileName := filepath.Base(groups[i].FileName)
+		if decoded > 0 {
+			logger.Info(fmt.Sprintf("Decoded %d raw calls of %s with the target ABI", decoded, fileName), "file", groups[i].FileName, "decoded", decoded)
+		}
+		if matched > 0 {
+			logger.Info(fmt.Sprintf("Matched %d deployments of %s with the artifacts", matched, fileName), "file", groups[i].FileName, "matched", matched)
+		}
+
+		raw := parser.CountCalls(calls, types.CallKindRaw)
+		switch {
+		case raw == 0:
+		case contractABI == nil:
+			logger.Warn(fmt.Sprintf("%s has %d calls replayed as raw calldata (use --abi or --artifacts to decode them)", fileName, raw), "file", groups[i].FileName, "raw", raw)
+		default:
+			logger.Warn(fmt.Sprintf("%s has %d calls that match no function of the target ABI, replayed as raw calldata", fileName, raw), "file", groups[i].FileName, "raw", raw)
+		}
+
+		deployments := 0
+		for _, call := range calls {
+			if call.Kind == types.CallKindCreate && call.ContractName == "" {
+				deployments++
+			}
+		}
+		switch {
+		case deployments == 0:
+		case artifacts == nil:
+			logger.Warn(fmt.Sprintf("%s deploys %d contracts from raw creation code (use --artifacts to deploy them with new)", fileName, deployments), "file", groups[i].FileName, "deployments", deployments)
+		default:
+			logger.Warn(fmt.Sprintf("%s deploys %d contracts whose creation code matches no artifact", fileName, deployments), "file", groups[i].FileName, "deployments", deployments)
+		}
+	}
+}
+
+// parseResult is the outcome of parsing one replay file
+type parseResult struct {
+	calls []types.ParsedCall
+	err   error
+}
+
+// parseFiles parses the replay files with a pool of up to jobs workers (the number of CPUs
+// when jobs is 0), returning the results in the order of the files
+func parseFiles(ctx context.Context, replayFiles []files.FileInfo, jobs int) ([]parseResult, error) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = min(jobs, len(replayFiles))
+
+	results := make([]parseResult, len(replayFiles))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				calls, err := parseReplayFile(replayFiles[i].Path)
+				results[i] = parseResult{calls: calls, err: err}
+			}
+		}()
+	}
+
+feed:
+	for i := range replayFiles {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// parseReplayFile parses a reproducer file, or standard input for files.StdinPath
+func parseReplayFile(path string) ([]types.ParsedCall, error) {
+	if path == files.StdinPath {
+		return parser.ParseReproducer(os.Stdin)
+	}
+	return parser.ParseReproducerFile(path)
+}
+
 // GenerateTestFunctionName creates a test function name and returns both the name and the last function
 func GenerateTestFunctionName(filePath string, number string, calls []types.ParsedCall) (testName string, lastFunction string) {
 	// Create test prefix based on number
@@ -0,0 +1,150 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/files"
+	"github.com/Enigma-Dark/runes/internal/logger"
+)
+
+// writeCorpus writes count synthetic Echidna reproducers of calls transactions each.
+// Every file deposits a different amount, so no two files are equal.
+func writeCorpus(tb testing.TB, count, calls int) []files.FileInfo {
+	previous := logger.Output
+	logger.Output = io.Discard
+	tb.Cleanup(func() { logger.Output = previous })
+
+	dir := tb.TempDir()
+	var corpus []files.FileInfo
+	for i := 0; i < count; i++ {
+		transactions := make([]string, calls)
+		for j := range transactions {
+			transactions[j] = fmt.Sprintf(`{"call": {"tag": "SolCall", "contents": ["deposit", [{"tag": "AbiUInt", "contents": [256, "%d"]}]]},
+				"src": "0x0000000000000000000000000000000000010000", "dst": "0x00a329c0648769A73afAc7F9381E08FB43dBEA72",
+				"gas": 12500000, "gasprice": "0x0", "value": "0x0", "delay": ["0x0", "0x0"]}`, i*calls+j)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%05d.txt", i))
+		require.NoError(tb, os.WriteFile(path, []byte("["+strings.Join(transactions, ",")+"]"), 0644))
+		corpus = append(corpus, files.FileInfo{Path: path})
+	}
+	return corpus
+}
+
+func TestProcessFiles_Parallel(t *testing.T) {
+	corpus := writeCorpus(t, 20, 3)
+	require.NoError(t, os.WriteFile(corpus[7].Path, []byte("not json"), 0644))
+
+	log := logger.NewProcessorLogger()
+	groups, err := ProcessFiles(context.Background(), corpus, ProcessOptions{Jobs: 4, Logger: log})
+	require.NoError(t, err)
+
+	// Results keep the order of the input files, whichever worker parsed them
+	require.Len(t, groups, 19)
+	for i, group := range groups {
+		expected := corpus[i]
+		if i >= 7 {
+			expected = corpus[i+1]
+		}
+		assert.Equal(t, expected.Path, group.FileName)
+		assert.Len(t, group.Calls, 3)
+	}
+
+	stats := log.GetStats()
+	assert.Equal(t, 20, stats.TotalFiles)
+	require.Len(t, stats.FailedFiles, 1)
+	assert.Equal(t, corpus[7].Path, stats.FailedFiles[0].Path)
+}
+
+func TestProcessFiles_DedupeByProperty(t *testing.T) {
+	corpus := writeCorpus(t, 3, 1)
+	data, err := os.ReadFile(corpus[0].Path)
+	require.NoError(t, err)
+
+	// The same sequence breaking two properties, and a copy of the first one
+	dir := filepath.Dir(corpus[0].Path)
+	var replays []files.FileInfo
+	for _, name := range []string{"invariant_a.txt", "invariant_b.txt", "invariant_a-2.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		replays = append(replays, files.FileInfo{Path: path})
+	}
+
+	groups, err := ProcessFiles(context.Background(), replays, ProcessOptions{Dedupe: true, DedupeThreshold: 1})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "invariant_a", groups[0].Property)
+	assert.Equal(t, "invariant_b", groups[1].Property)
+}
+
+func TestProcessFiles_AllFailed(t *testing.T) {
+	corpus := writeCorpus(t, 3, 1)
+	require.NoError(t, os.WriteFile(corpus[0].Path, []byte("not json"), 0644))
+	require.NoError(t, os.WriteFile(corpus[2].Path, []byte("[]"), 0644))
+	corpus = append(corpus[:1], corpus[2])
+
+	_, err := ProcessFiles(context.Background(), corpus, ProcessOptions{Jobs: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all 2 files failed to process")
+	assert.Contains(t, err.Error(), "00000.txt: ")
+	assert.Contains(t, err.Error(), "00002.txt: no valid calls found")
+}
+
+func TestProcessFiles_Cancelled(t *testing.T) {
+	corpus := writeCorpus(t, 5, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ProcessFiles(ctx, corpus, ProcessOptions{Jobs: 2})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProcessFiles_Corpus(t *testing.T) {
+	corpus := writeCorpus(t, 3, 2)
+	corpus[0].Origin = files.OriginReproducers
+	corpus[1].Origin = files.OriginCoverage
+	corpus[2].Origin = files.OriginCoverage
+
+	groups, err := ProcessFiles(context.Background(), corpus, ProcessOptions{
+		Property:  "echidna_solvency",
+		Selection: Selection{Top: 1},
+	})
+	require.NoError(t, err)
+
+	// Coverage sequences broke no property, so they are not checked
+	require.Len(t, groups, 2)
+	assert.Equal(t, files.OriginReproducers, groups[0].Origin)
+	assert.Equal(t, "echidna_solvency", groups[0].Property)
+	assert.Equal(t, files.OriginCoverage, groups[1].Origin)
+	assert.Empty(t, groups[1].Property)
+
+	_, err = ProcessFiles(context.Background(), corpus, ProcessOptions{Selection: Selection{Functions: []string{"withdraw"}}})
+	assert.ErrorContains(t, err, "none of the 3 parsed sequences matches the selection")
+}
+
+// BenchmarkProcessFiles compares sequential and parallel parsing of a synthetic corpus
+func BenchmarkProcessFiles(b *testing.B) {
+	corpus := writeCorpus(b, 500, 50)
+
+	for _, jobs := range []int{1, 4, 0} {
+		name := fmt.Sprintf("jobs=%d", jobs)
+		if jobs == 0 {
+			name = "jobs=cpus"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := ProcessFiles(context.Background(), corpus, ProcessOptions{Jobs: jobs}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package replay
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// Selection keeps the output of large corpora manageable. Each limit applies to every
+// origin separately, so coverage sequences cannot crowd out reproducers; the zero value
+// keeps all sequences.
+type Selection struct {
+	Functions []string // Keep sequences calling one of these functions (all when empty)
+	Top       int      // Keep the N longest sequences (0 for no limit)
+	Sample    int      // Keep a random sample of N sequences (0 for no limit)
+	Seed      int64    // Seed of the random sample, the same seed selects the same sequences
+}
+
+// IsZero reports whether the selection keeps all sequences
+func (s Selection) IsZero() bool {
+	return len(s.Functions) == 0 && s.Top <= 0 && s.Sample <= 0
+}
+
+// Select applies the selection to the groups of each origin in turn: the function filter,
+// then the N longest, then the random sample. Selected groups keep their order.
+func Select(groups []types.ReplayGroup, selection Selection) []types.ReplayGroup {
+	if selection.IsZero() {
+		return groups
+	}
+
+	var origins []string
+	byOrigin := make(map[string][]int)
+	for i, group := range groups {
+		if _, ok := byOrigin[group.Origin]; !ok {
+			origins = append(origins, group.Origin)
+		}
+		byOrigin[group.Origin] = append(byOrigin[group.Origin], i)
+	}
+
+	var kept []int
+	for _, origin := range origins {
+		kept = append(kept, selectIndexes(groups, byOrigin[origin], selection)...)
+	}
+	sort.Ints(kept)
+
+	result := make([]types.ReplayGroup, len(kept))
+	for i, index := range kept {
+		result[i] = groups[index]
+	}
+	return result
+}
+
+// selectIndexes applies the selection to the groups at indexes
+func selectIndexes(groups []types.ReplayGroup, indexes []int, selection Selection) []int {
+	if len(selection.Functions) > 0 {
+		var calling []int
+		for _, index := range indexes {
+			if callsAny(groups[index].Calls, selection.Functions) {
+				calling = append(calling, index)
+			}
+		}
+		indexes = calling
+	}
+
+	if selection.Top > 0 && len(indexes) > selection.Top {
+		longest := append([]int(nil), indexes...)
+		sort.SliceStable(longest, func(i, j int) bool {
+			return len(groups[longest[i]].Calls) > len(groups[longest[j]].Calls)
+		})
+		indexes = longest[:selection.Top]
+	}
+
+	if selection.Sample > 0 && len(indexes) > selection.Sample {
+		random := rand.New(rand.NewSource(selection.Seed))
+		sample := make([]int, selection.Sample)
+		for i, position := range random.Perm(len(indexes))[:selection.Sample] {
+			sample[i] = indexes[position]
+		}
+		indexes = sample
+	}
+	return indexes
+}
+
+// callsAny reports whether one of the calls is to one of the named functions
+func callsAny(calls []types.ParsedCall, functions []string) bool {
+	for _, call := range calls {
+		for _, name := range functions {
+			if call.FunctionName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
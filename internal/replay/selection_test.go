@@ -0,0 +1,41 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Enigma-Dark/runes/internal/files"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// corpusGroup creates a replay group of an origin calling the named functions
+func corpusGroup(fileName, origin string, functions ...string) types.ReplayGroup {
+	group := types.ReplayGroup{FileName: fileName, Origin: origin}
+	for _, name := range functions {
+		group.Calls = append(group.Calls, types.ParsedCall{FunctionName: name})
+	}
+	return group
+}
+
+func TestSelect(t *testing.T) {
+	groups := []types.ReplayGroup{
+		corpusGroup("r1", files.OriginReproducers, "deposit"),
+		corpusGroup("c1", files.OriginCoverage, "deposit"),
+		corpusGroup("c2", files.OriginCoverage, "deposit", "withdraw", "deposit"),
+		corpusGroup("c3", files.OriginCoverage, "withdraw", "withdraw"),
+		corpusGroup("c4", files.OriginCoverage, "deposit", "deposit", "deposit", "deposit"),
+	}
+
+	assert.Equal(t, groups, Select(groups, Selection{}))
+
+	// Limits apply to each origin, and selected groups keep their order
+	assert.Equal(t, []string{"r1", "c2", "c4"}, fileNames(Select(groups, Selection{Top: 2})))
+	assert.Equal(t, []string{"c2", "c3"}, fileNames(Select(groups, Selection{Functions: []string{"withdraw"}})))
+	assert.Equal(t, []string{"c2"}, fileNames(Select(groups, Selection{Functions: []string{"withdraw"}, Top: 1})))
+
+	sample := Select(groups, Selection{Sample: 2, Seed: 7})
+	assert.Len(t, sample, 3)
+	assert.Equal(t, "r1", sample[0].FileName)
+	assert.Equal(t, sample, Select(groups, Selection{Sample: 2, Seed: 7}), "the same seed selects the same sequences")
+}
@@ -0,0 +1,213 @@
+package shrink
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// Oracle decides whether a candidate call sequence still reproduces the failure
+type Oracle interface {
+	Reproduces(ctx context.Context, calls []types.ParsedCall) (bool, error)
+}
+
+// OracleFunc adapts a function to the Oracle interface
+type OracleFunc func(ctx context.Context, calls []types.ParsedCall) (bool, error)
+
+// Reproduces calls f(ctx, calls)
+func (f OracleFunc) Reproduces(ctx context.Context, calls []types.ParsedCall) (bool, error) {
+	return f(ctx, calls)
+}
+
+// Minimizer shrinks call sequences with delta debugging (ddmin)
+type Minimizer struct {
+	Oracle Oracle
+
+	// OnTest is called after every oracle run with the candidate size and outcome (optional)
+	OnTest func(size int, reproduces bool)
+
+	cache map[string]bool
+	runs  int
+}
+
+// Runs returns the number of times the oracle was invoked
+func (m *Minimizer) Runs() int {
+	return m.runs
+}
+
+// Minimize returns a 1-minimal subsequence of calls that still reproduces the failure:
+// removing any single remaining call makes the failure disappear. The full sequence is
+// tested first, and minimizing stops with an error unless it reproduces the failure.
+func (m *Minimizer) Minimize(ctx context.Context, calls []types.ParsedCall) ([]types.ParsedCall, error) {
+	m.cache = make(map[string]bool)
+
+	indices := make([]int, len(calls))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	reproduces, err := m.test(ctx, calls, indices)
+	if err != nil {
+		return nil, err
+	}
+	if !reproduces {
+		return nil, fmt.Errorf("the oracle does not reproduce the failure with the full sequence of %d calls", len(calls))
+	}
+
+	granularity := 2
+	for len(indices) >= 2 {
+		chunks := split(indices, granularity)
+		reduced := false
+
+		// Try each chunk on its own
+		for _, chunk := range chunks {
+			if reproduces, err = m.test(ctx, calls, chunk); err != nil {
+				return nil, err
+			}
+			if reproduces {
+				indices, granularity, reduced = chunk, 2, true
+				break
+			}
+		}
+
+		// Try removing each chunk
+		if !reduced {
+			for i := range chunks {
+				complement := complementOf(chunks, i)
+				if reproduces, err = m.test(ctx, calls, complement); err != nil {
+					return nil, err
+				}
+				if reproduces {
+					indices, granularity, reduced = complement, max(granularity-1, 2), true
+					break
+				}
+			}
+		}
+
+		if !reduced {
+			if granularity >= len(indices) {
+				break
+			}
+			granularity = min(granularity*2, len(indices))
+		}
+	}
+
+	return selectCalls(calls, indices)
+}
+
+// test runs the oracle on the calls at the given indices, caching results per subsequence
+func (m *Minimizer) test(ctx context.Context, calls []types.ParsedCall, indices []int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	key := cacheKey(indices)
+	if result, ok := m.cache[key]; ok {
+		return result, nil
+	}
+
+	candidate, err := selectCalls(calls, indices)
+	if err != nil {
+		return false, err
+	}
+
+	m.runs++
+	reproduces, err := m.Oracle.Reproduces(ctx, candidate)
+	if err != nil {
+		return false, fmt.Errorf("oracle failed: %w", err)
+	}
+
+	m.cache[key] = reproduces
+	if m.OnTest != nil {
+		m.OnTest(len(indices), reproduces)
+	}
+	return reproduces, nil
+}
+
+// split divides indices into n chunks of nearly equal size
+func split(indices []int, n int) [][]int {
+	var chunks [][]int
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(indices)-start)/(n-i)
+		if end > start {
+			chunks = append(chunks, indices[start:end])
+		}
+		start = end
+	}
+	return chunks
+}
+
+// complementOf returns all indices except those in chunks[skip]
+func complementOf(chunks [][]int, skip int) []int {
+	var result []int
+	for i, chunk := range chunks {
+		if i != skip {
+			result = append(result, chunk...)
+		}
+	}
+	return result
+}
+
+// selectCalls returns the calls at the given ascending indices. The delays of removed calls
+// are added to the next kept call, so the kept calls run at the same time and block as in the
+// full sequence; delays after the last kept call are dropped.
+func selectCalls(calls []types.ParsedCall, indices []int) ([]types.ParsedCall, error) {
+	result := make([]types.ParsedCall, 0, len(indices))
+	timeDelay, blockDelay := new(big.Int), new(big.Int)
+
+	next := 0
+	for i := 0; next < len(indices); i++ {
+		call := calls[i]
+		callTime, err := parseDelay(call.HasDelay, call.DelayValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time delay of call %d: %w", i, err)
+		}
+		callBlocks, err := parseDelay(call.HasBlockDelay, call.BlockDelayValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block delay of call %d: %w", i, err)
+		}
+		timeDelay.Add(timeDelay, callTime)
+		blockDelay.Add(blockDelay, callBlocks)
+
+		if i != indices[next] {
+			continue
+		}
+		next++
+
+		if timeDelay.Sign() > 0 {
+			call.HasDelay, call.DelayValue = true, timeDelay.String()
+		}
+		if blockDelay.Sign() > 0 {
+			call.HasBlockDelay, call.BlockDelayValue = true, blockDelay.String()
+		}
+		result = append(result, call)
+		timeDelay, blockDelay = new(big.Int), new(big.Int)
+	}
+	return result, nil
+}
+
+// parseDelay parses a decimal delay, which is zero when the call has none
+func parseDelay(hasDelay bool, value string) (*big.Int, error) {
+	delay := new(big.Int)
+	if !hasDelay {
+		return delay, nil
+	}
+	if _, ok := delay.SetString(value, 10); !ok || delay.Sign() < 0 {
+		return nil, fmt.Errorf("not a decimal number: %q", value)
+	}
+	return delay, nil
+}
+
+// cacheKey identifies a subsequence by its indices
+func cacheKey(indices []int) string {
+	parts := make([]string, len(indices))
+	for i, index := range indices {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, ",")
+}
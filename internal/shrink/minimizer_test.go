@@ -0,0 +1,199 @@
+package shrink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// sequence builds a call sequence from function names
+func sequence(names ...string) []types.ParsedCall {
+	var calls []types.ParsedCall
+	for _, name := range names {
+		calls = append(calls, types.ParsedCall{
+			FunctionName: name,
+			Src:          "0x0000000000000000000000000000000000010000",
+		})
+	}
+	return calls
+}
+
+// functionNames returns the function names of a call sequence
+func functionNames(calls []types.ParsedCall) []string {
+	var names []string
+	for _, call := range calls {
+		names = append(names, call.FunctionName)
+	}
+	return names
+}
+
+func TestMinimize(t *testing.T) {
+	// The failure needs "deposit" followed later by "withdraw"
+	oracle := OracleFunc(func(ctx context.Context, calls []types.ParsedCall) (bool, error) {
+		deposited := false
+		for _, call := range calls {
+			if call.FunctionName == "deposit" {
+				deposited = true
+			}
+			if call.FunctionName == "withdraw" && deposited {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	minimizer := &Minimizer{Oracle: oracle}
+	minimized, err := minimizer.Minimize(context.Background(),
+		sequence("setPrice", "deposit", "borrow", "setPrice", "repay", "withdraw", "borrow"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deposit", "withdraw"}, functionNames(minimized))
+	assert.Greater(t, minimizer.Runs(), 1)
+}
+
+func TestMinimize_KeepsDelays(t *testing.T) {
+	calls := sequence("deposit", "borrow", "withdraw", "repay")
+	calls[0].HasDelay, calls[0].DelayValue = true, "10"
+	calls[0].HasBlockDelay, calls[0].BlockDelayValue = true, "1"
+	calls[1].HasDelay, calls[1].DelayValue = true, "5"
+	calls[1].HasBlockDelay, calls[1].BlockDelayValue = true, "2"
+	calls[2].HasDelay, calls[2].DelayValue = true, "2"
+	calls[3].HasDelay, calls[3].DelayValue = true, "7"
+
+	// The failure needs a withdraw at least 7 seconds after the deposit
+	oracle := OracleFunc(func(ctx context.Context, calls []types.ParsedCall) (bool, error) {
+		var elapsed int
+		deposited := false
+		for _, call := range calls {
+			delay, _ := strconv.Atoi(call.DelayValue)
+			elapsed += delay
+			if call.FunctionName == "deposit" {
+				deposited, elapsed = true, 0
+			}
+			if call.FunctionName == "withdraw" && deposited && elapsed >= 7 {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	minimized, err := (&Minimizer{Oracle: oracle}).Minimize(context.Background(), calls)
+	require.NoError(t, err)
+	require.Equal(t, []string{"deposit", "withdraw"}, functionNames(minimized))
+
+	// The removed borrow's delays move to the withdraw, the trailing repay's are dropped
+	assert.Equal(t, "10", minimized[0].DelayValue)
+	assert.Equal(t, "1", minimized[0].BlockDelayValue)
+	assert.Equal(t, "7", minimized[1].DelayValue)
+	assert.True(t, minimized[1].HasBlockDelay)
+	assert.Equal(t, "2", minimized[1].BlockDelayValue)
+
+	// The input sequence is left untouched
+	assert.Equal(t, "2", calls[2].DelayValue)
+	assert.False(t, calls[2].HasBlockDelay)
+}
+
+func TestMinimize_NotReproducing(t *testing.T) {
+	oracle := OracleFunc(func(ctx context.Context, calls []types.ParsedCall) (bool, error) {
+		return false, nil
+	})
+
+	minimizer := &Minimizer{Oracle: oracle}
+	_, err := minimizer.Minimize(context.Background(), sequence("deposit", "withdraw"))
+	assert.ErrorContains(t, err, "does not reproduce the failure with the full sequence")
+	assert.Equal(t, 1, minimizer.Runs())
+}
+
+func TestCommandOracle_StubScript(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Shrink.t.sol")
+
+	// Stub oracle: "fails" while the generated test still calls both deposit and withdraw
+	script := filepath.Join(tmpDir, "oracle.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+grep -q "Tester.deposit(" "$RUNES_TEST_FILE" && grep -q "Tester.withdraw(" "$1" && exit 1
+exit 0
+`), 0755)
+	require.NoError(t, err)
+
+	oracle := &CommandOracle{
+		Command:  "sh " + script + " {file}",
+		TestName: "test_shrink",
+		Config: generator.GenerateConfig{
+			ContractName: "Shrink",
+			OutputFile:   testFile,
+			Template:     "basic",
+		},
+	}
+
+	minimizer := &Minimizer{Oracle: oracle}
+	minimized, err := minimizer.Minimize(context.Background(),
+		sequence("setPrice", "deposit", "borrow", "withdraw", "repay"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deposit", "withdraw"}, functionNames(minimized))
+
+	content, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "function test_shrink()")
+}
+
+func TestCommandOracle_BuildFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	oracle := &CommandOracle{
+		Command:  "exit 1",
+		Build:    `echo "Error: Compiler run failed" >&2; exit 1`,
+		TestName: "test_shrink",
+		Config: generator.GenerateConfig{
+			ContractName: "Shrink",
+			OutputFile:   filepath.Join(tmpDir, "Shrink.t.sol"),
+			Template:     "basic",
+		},
+	}
+
+	// A test that does not compile is an error, not a reproduction
+	minimizer := &Minimizer{Oracle: oracle}
+	_, err := minimizer.Minimize(context.Background(), sequence("deposit", "withdraw"))
+	assert.ErrorContains(t, err, "failed to build candidate test")
+	assert.ErrorContains(t, err, "Compiler run failed")
+	assert.Equal(t, 1, minimizer.Runs())
+
+	oracle.Build = "exit 0"
+	reproduces, err := oracle.Reproduces(context.Background(), sequence("deposit"))
+	require.NoError(t, err)
+	assert.True(t, reproduces)
+}
+
+func TestCommandOracle_Property(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "Shrink.t.sol")
+
+	// Stub oracle: the property check alone makes the test fail
+	oracle := &CommandOracle{
+		Command:  `grep -q 'assertTrue(Tester.echidna_solvency()' {file} && exit 1; exit 0`,
+		TestName: "test_shrink",
+		Property: "echidna_solvency",
+		Config: generator.GenerateConfig{
+			ContractName: "Shrink",
+			OutputFile:   testFile,
+			Template:     "basic",
+		},
+	}
+	reproduces, err := oracle.Reproduces(context.Background(), sequence("deposit"))
+	require.NoError(t, err)
+	assert.True(t, reproduces)
+
+	// Candidates without the function breaking an assertion do not reproduce it
+	oracle.Command = "exit 1"
+	oracle.Property = "withdraw(uint256)"
+	minimizer := &Minimizer{Oracle: oracle}
+	minimized, err := minimizer.Minimize(context.Background(), sequence("deposit", "withdraw"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"withdraw"}, functionNames(minimized))
+}
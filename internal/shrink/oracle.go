@@ -0,0 +1,118 @@
+package shrink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// buildOutputLines is the number of trailing build output lines kept in build errors
+const buildOutputLines = 20
+
+// CommandOracle regenerates the replay test for each candidate and runs a shell command
+// (e.g. "forge test --mt test_shrink"). The failure reproduces when the command exits non-zero.
+//
+// As a test that does not compile also makes the command fail, the candidate is first compiled
+// with the build command (e.g. "forge build"), whose failure is an error rather than a
+// reproduction. Oracles that compile nothing can leave it empty.
+//
+// The placeholders {test} and {file} in the commands are replaced with the test function name
+// and the generated test file, which are also exported as RUNES_TEST_NAME and RUNES_TEST_FILE.
+type CommandOracle struct {
+	Command  string
+	Build    string // Command compiling the candidate test before the oracle runs (optional)
+	TestName string
+	Property string                   // Property broken by the reproducer, checked at the end of the candidate test (optional)
+	Config   generator.GenerateConfig // Output file, contract name and template of the candidate test
+	Dir      string                   // Working directory of the commands (defaults to the current one)
+}
+
+// Reproduces writes the candidate test and reports whether the command still fails
+func (o *CommandOracle) Reproduces(ctx context.Context, calls []types.ParsedCall) (bool, error) {
+	if len(calls) == 0 {
+		return false, nil
+	}
+
+	// An assertion failure is not reproduced once the function breaking it is removed
+	if name, ok := assertionFunction(o.Property); ok && !callsFunction(calls, name) {
+		return false, nil
+	}
+
+	config := o.Config
+	config.ReplayGroups = []types.ReplayGroup{{
+		TestName: o.TestName,
+		Calls:    calls,
+		Property: o.Property,
+	}}
+	if err := generator.GenerateFoundryTest(config); err != nil {
+		return false, fmt.Errorf("failed to generate candidate test: %w", err)
+	}
+
+	if o.Build != "" {
+		var output bytes.Buffer
+		build := o.command(ctx, o.Build)
+		build.Stdout, build.Stderr = &output, &output
+		if err := build.Run(); err != nil {
+			return false, fmt.Errorf("failed to build candidate test %s: %w\n%s", o.Config.OutputFile, err, tail(output.String(), buildOutputLines))
+		}
+	}
+
+	err := o.command(ctx, o.Command).Run()
+	if err == nil {
+		return false, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to run oracle command: %w", err)
+}
+
+// command prepares a shell command with the placeholders and environment of the candidate test
+func (o *CommandOracle) command(ctx context.Context, command string) *exec.Cmd {
+	command = strings.NewReplacer("{test}", o.TestName, "{file}", o.Config.OutputFile).Replace(command)
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = o.Dir
+	cmd.Env = append(os.Environ(),
+		"RUNES_TEST_NAME="+o.TestName,
+		"RUNES_TEST_FILE="+o.Config.OutputFile,
+	)
+	return cmd
+}
+
+// assertionFunction returns the function named by an assertion failure, which is reported
+// with its signature (e.g. check_balance(uint256)) rather than a property name
+func assertionFunction(property string) (string, bool) {
+	paren := strings.IndexByte(property, '(')
+	if paren <= 0 || !strings.HasSuffix(property, ")") {
+		return "", false
+	}
+	return property[:paren], true
+}
+
+// callsFunction reports whether a call sequence calls the named function
+func callsFunction(calls []types.ParsedCall, name string) bool {
+	for _, call := range calls {
+		if call.FunctionName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tail returns the last n lines of output
+func tail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -6,13 +6,39 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 )
 
 //go:embed builtin/*.tmpl
 var builtinTemplates embed.FS
 
+// registered holds templates registered for the whole process, e.g. by library users
+var (
+	registeredMu sync.RWMutex
+	registered   = make(map[string]*template.Template)
+)
+
+// Register parses a template and makes it available to every manager under name.
+// Registered templates take precedence over builtin templates of the same name.
+func Register(name, content string) error {
+	if name == "" {
+		return fmt.Errorf("template name is required")
+	}
+
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[name] = tmpl
+	return nil
+}
+
 // Manager handles template registration and retrieval
 type Manager struct {
 	templates map[string]*template.Template
@@ -54,6 +80,16 @@ func (m *Manager) LoadBuiltinTemplates() error {
 	})
 }
 
+// LoadRegisteredTemplates adds the templates registered with Register
+func (m *Manager) LoadRegisteredTemplates() {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	for name, tmpl := range registered {
+		m.templates[name] = tmpl
+	}
+}
+
 // LoadExternalTemplate loads a template from an external file
 func (m *Manager) LoadExternalTemplate(name, filePath string) error {
 	content, err := os.ReadFile(filePath)
@@ -86,6 +122,7 @@ func (m *Manager) ListTemplates() []string {
 	for name := range m.templates {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
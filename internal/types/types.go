@@ -14,10 +14,11 @@ type Transaction struct {
 	Value    string   `json:"value"`
 }
 
-// Call represents the function call within a transaction
+// Call represents the function call within a transaction.
+// Contents is [name, parameters] for SolCall and the raw bytes for SolCalldata and SolCreate.
 type Call struct {
-	Contents []interface{} `json:"contents,omitempty"`
-	Tag      string        `json:"tag"`
+	Contents interface{} `json:"contents,omitempty"`
+	Tag      string      `json:"tag"`
 }
 
 // AbiParam represents a parameter with ABI type information
@@ -32,23 +33,40 @@ type SolCall struct {
 	Parameters   []AbiParam `json:"parameters"`
 }
 
+// CallKind distinguishes transactions that are not plain function calls
+type CallKind string
+
+const (
+	CallKindFunction    CallKind = ""            // Call by function name, or a pure delay when FunctionName is empty
+	CallKindRaw         CallKind = "raw"         // Low-level call with Calldata that could not be decoded
+	CallKindCreate      CallKind = "create"      // Contract deployment with the creation code in Calldata
+	CallKindUnsupported CallKind = "unsupported" // Transaction that cannot be replayed, described by Note
+)
+
 // ParsedCall represents a parsed function call for easier processing
 type ParsedCall struct {
-	FunctionName string
-	Parameters   []ParsedParam
-	Dst          string
-	Src          string
-	Value        string
-	Gas          int64
-	GasPrice     string
-	HasDelay     bool   // Whether this call has an associated delay
-	DelayValue   string // The delay value in seconds
+	Kind            CallKind
+	FunctionName    string
+	Parameters      []ParsedParam
+	Dst             string
+	Src             string
+	Value           string
+	Gas             int64
+	GasPrice        string
+	HasDelay        bool   // Whether this call has an associated time delay
+	DelayValue      string // The time delay in seconds
+	HasBlockDelay   bool   // Whether this call has an associated block delay
+	BlockDelayValue string // The block delay in number of blocks
+	Calldata        string // 0x-prefixed calldata of raw calls, or creation code and constructor arguments of deployments
+	ContractName    string // Contract deployed by a create call, when its artifact is known (Parameters then holds the constructor arguments)
+	Note            string // Why an unsupported transaction cannot be replayed
 }
 
 // ParsedParam represents a parsed parameter
 type ParsedParam struct {
-	Type  string // Solidity type (uint256, uint8, etc.)
-	Value string // The actual value
+	Type     string        // Solidity type (uint256, uint8[], (uint256,address), etc.)
+	Value    string        // The actual value (empty for arrays and tuples)
+	Elements []ParsedParam // Array elements or tuple components, in order
 }
 
 // ReplayGroup represents a group of calls that form one test function
@@ -56,4 +74,53 @@ type ReplayGroup struct {
 	TestName string       // The name of the test function
 	Calls    []ParsedCall // The sequence of calls in this replay
 	FileName string       // Original file name for reference
+	Property string       // Property broken by the replay, checked at its end (optional): a property function, or a function signature for assertion failures
+	Origin   string       // Corpus folder the replayed sequence comes from, e.g. coverage (optional)
+}
+
+// MedusaCallSequence represents the root structure of a Medusa call sequence file
+type MedusaCallSequence []MedusaCallSequenceElement
+
+// MedusaCallSequenceElement represents a single entry in a Medusa call sequence
+type MedusaCallSequenceElement struct {
+	Call                MedusaCall `json:"call"`
+	BlockNumberDelay    uint64     `json:"blockNumberDelay"`
+	BlockTimestampDelay uint64     `json:"blockTimestampDelay"`
+}
+
+// MedusaCall represents the message sent by Medusa for one call sequence element
+type MedusaCall struct {
+	From          string               `json:"from"`
+	To            string               `json:"to"`
+	Nonce         interface{}          `json:"nonce"`
+	Value         interface{}          `json:"value"`
+	GasLimit      interface{}          `json:"gasLimit"`
+	GasPrice      interface{}          `json:"gasPrice"`
+	Data          string               `json:"data"`
+	DataAbiValues *MedusaDataAbiValues `json:"dataAbiValues"`
+}
+
+// MedusaDataAbiValues holds the decoded method and arguments of a Medusa call
+type MedusaDataAbiValues struct {
+	MethodSignature string        `json:"methodSignature"`
+	InputValues     []interface{} `json:"inputValues"`
+}
+
+// FoundryPersistedFailure is an invariant failure Foundry persists under cache/invariant/failures
+type FoundryPersistedFailure struct {
+	CallSequence []FoundryTxDetails `json:"call_sequence"`
+}
+
+// FoundryTxDetails is one call of a persisted Foundry invariant failure
+type FoundryTxDetails struct {
+	Warp        interface{}        `json:"warp"` // Time delay added before the call (newer versions only)
+	Roll        interface{}        `json:"roll"` // Block delay added before the call (newer versions only)
+	Sender      string             `json:"sender"`
+	CallDetails FoundryCallDetails `json:"call_details"`
+}
+
+// FoundryCallDetails holds the target and raw calldata of a persisted Foundry call
+type FoundryCallDetails struct {
+	Target   string `json:"target"`
+	Calldata string `json:"calldata"`
 }
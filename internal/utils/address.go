@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// NormalizeAddress validates an address and returns it as 40 lowercase hex digits with 0x prefix.
+// Short addresses such as "0x10000" are left-padded with zeros.
+func NormalizeAddress(address string) (string, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if len(digits) == 0 || len(digits) > 40 {
+		return "", fmt.Errorf("invalid address: %q", address)
+	}
+
+	digits = strings.ToLower(digits)
+	if _, err := hex.DecodeString(strings.Repeat("0", len(digits)%2) + digits); err != nil {
+		return "", fmt.Errorf("invalid address: %q", address)
+	}
+
+	return "0x" + strings.Repeat("0", 40-len(digits)) + digits, nil
+}
+
+// ChecksumAddress returns the EIP-55 mixed-case checksum encoding of an address,
+// which Solidity requires for address literals
+func ChecksumAddress(address string) (string, error) {
+	normalized, err := NormalizeAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	digits := normalized[2:]
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(digits))
+	hashHex := hex.EncodeToString(hash.Sum(nil))
+
+	var result strings.Builder
+	result.WriteString("0x")
+	for i, c := range digits {
+		if c >= 'a' && c <= 'f' && hashHex[i] >= '8' {
+			result.WriteRune(c - 'a' + 'A')
+		} else {
+			result.WriteRune(c)
+		}
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// EIP-55 reference vectors
+	for _, expected := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	} {
+		checksummed, err := ChecksumAddress(expected)
+		require.NoError(t, err)
+		assert.Equal(t, expected, checksummed)
+	}
+
+	// Short addresses are padded
+	checksummed, err := ChecksumAddress("0x10000")
+	require.NoError(t, err)
+	assert.Equal(t, "0x0000000000000000000000000000000000010000", checksummed)
+
+	_, err = ChecksumAddress("0xnotanaddress")
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseHexQuantity parses an arbitrary-precision 0x-prefixed hex quantity, such as the
+// values, gas prices and delays of Echidna transactions. An empty quantity is zero.
+func ParseHexQuantity(value string) (*big.Int, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	if digits == "" {
+		return new(big.Int), nil
+	}
+
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("not a hex number: %q", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHexQuantity(t *testing.T) {
+	for value, expected := range map[string]string{
+		"":     "0",
+		"0x":   "0",
+		"0x0":  "0",
+		"0X1f": "31",
+		"0x00000000000000000000000000000000000000000000000000000000000003e8": "1000",
+		"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff": "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+	} {
+		n, err := ParseHexQuantity(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, n.String(), value)
+	}
+
+	for _, value := range []string{"0xzz", "0x-1"} {
+		_, err := ParseHexQuantity(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,402 @@
+package validate
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Call tags of Echidna transactions
+var callTags = []string{"SolCall", "SolCalldata", "SolCreate", "NoCall"}
+
+// Tags of Echidna ABI values
+var valueTags = []string{"AbiUInt", "AbiInt", "AbiAddress", "AbiBool", "AbiBytes", "AbiBytesDynamic",
+	"AbiString", "AbiArrayDynamic", "AbiArray", "AbiTuple"}
+
+// checkReproducer checks an Echidna reproducer: an array of transactions
+func (v *validator) checkReproducer(root *node) {
+	if root.kind != kindArray {
+		v.report(root, "", SeverityError, "expected an array of transactions, found %s", root.kind)
+		return
+	}
+	if len(root.items) == 0 {
+		v.report(root, "", SeverityWarning, "reproducer has no transactions")
+	}
+
+	for i, tx := range root.items {
+		v.checkTransaction(tx, joinIndex("", i))
+	}
+}
+
+// checkTransaction checks one transaction: {call, src, dst, gas, gasprice, value, delay}
+func (v *validator) checkTransaction(tx *node, path string) {
+	if !v.expect(tx, path, kindObject) {
+		return
+	}
+	v.checkFields(tx, path, []string{"call", "src", "dst", "gas", "gasprice", "value", "delay"}, nil)
+
+	if call := tx.get("call"); call != nil {
+		v.checkCall(call, joinField(path, "call"))
+	}
+	if src := tx.get("src"); src != nil {
+		v.checkAddress(src, joinField(path, "src"))
+	}
+	if dst := tx.get("dst"); dst != nil {
+		v.checkAddress(dst, joinField(path, "dst"))
+	}
+	if gas := tx.get("gas"); gas != nil {
+		gasPath := joinField(path, "gas")
+		if v.expect(gas, gasPath, kindNumber) {
+			if _, err := strconv.ParseUint(gas.number.String(), 10, 64); err != nil {
+				v.report(gas, gasPath, SeverityError, "gas %s is not an unsigned 64-bit integer", gas.number)
+			}
+		}
+	}
+	if gasPrice := tx.get("gasprice"); gasPrice != nil {
+		v.checkHexQuantity(gasPrice, joinField(path, "gasprice"), 256)
+	}
+	if value := tx.get("value"); value != nil {
+		v.checkHexQuantity(value, joinField(path, "value"), 256)
+	}
+	if delay := tx.get("delay"); delay != nil {
+		delayPath := joinField(path, "delay")
+		if v.expect(delay, delayPath, kindArray) && v.expectLength(delay, delayPath, 2, "time and block delay") {
+			for i, item := range delay.items {
+				v.checkHexQuantity(item, joinIndex(delayPath, i), 256)
+			}
+		}
+	}
+}
+
+// checkCall checks the call of a transaction, tagged with its kind
+func (v *validator) checkCall(call *node, path string) {
+	if !v.expect(call, path, kindObject) {
+		return
+	}
+
+	tag, ok := v.checkTag(call, path, callTags)
+	if !ok {
+		return
+	}
+
+	contentsPath := joinField(path, "contents")
+	contents := call.get("contents")
+	switch tag {
+	case "NoCall":
+		v.checkFields(call, path, []string{"tag"}, []string{"contents"})
+	case "SolCalldata", "SolCreate":
+		if v.checkFields(call, path, []string{"tag", "contents"}, nil) {
+			v.checkByteString(contents, contentsPath)
+		}
+	case "SolCall":
+		if !v.checkFields(call, path, []string{"tag", "contents"}, nil) {
+			return
+		}
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 2, "function name and arguments") {
+			return
+		}
+
+		name, args := contents.items[0], contents.items[1]
+		namePath := joinIndex(contentsPath, 0)
+		if v.expect(name, namePath, kindString) && !isIdentifier(name.str) {
+			v.report(name, namePath, SeverityError, "%q is not a function name", name.str)
+		}
+
+		argsPath := joinIndex(contentsPath, 1)
+		if v.expect(args, argsPath, kindArray) {
+			for i, arg := range args.items {
+				v.checkValue(arg, joinIndex(argsPath, i))
+			}
+		}
+	}
+}
+
+// checkTag checks the tag member of a tagged object against the allowed tags
+func (v *validator) checkTag(n *node, path string, allowed []string) (string, bool) {
+	tag := n.get("tag")
+	tagPath := joinField(path, "tag")
+	if tag == nil {
+		v.report(n, path, SeverityError, "missing field %q", "tag")
+		return "", false
+	}
+	if !v.expect(tag, tagPath, kindString) {
+		return "", false
+	}
+	for _, candidate := range allowed {
+		if tag.str == candidate {
+			return tag.str, true
+		}
+	}
+	v.report(tag, tagPath, SeverityError, "unknown tag %q (expected one of %s)", tag.str, strings.Join(allowed, ", "))
+	return "", false
+}
+
+// checkValue checks an ABI value and returns its Solidity type, or "" when it is invalid
+func (v *validator) checkValue(value *node, path string) string {
+	if !v.expect(value, path, kindObject) {
+		return ""
+	}
+	tag, ok := v.checkTag(value, path, valueTags)
+	if !ok || !v.checkFields(value, path, []string{"tag", "contents"}, nil) {
+		return ""
+	}
+
+	contentsPath := joinField(path, "contents")
+	contents := value.get("contents")
+
+	switch tag {
+	case "AbiUInt", "AbiInt":
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 2, "bit size and value") {
+			return ""
+		}
+		size, ok := v.checkSize(contents.items[0], joinIndex(contentsPath, 0), 8, 256, 8)
+		if !ok {
+			return ""
+		}
+		signed := tag == "AbiInt"
+		if !v.checkInteger(contents.items[1], joinIndex(contentsPath, 1), size, signed) {
+			return ""
+		}
+		if signed {
+			return fmt.Sprintf("int%d", size)
+		}
+		return fmt.Sprintf("uint%d", size)
+
+	case "AbiAddress":
+		address, addressPath := unwrapSingle(contents, contentsPath)
+		v.checkAddress(address, addressPath)
+		return "address"
+
+	case "AbiBool":
+		boolean, boolPath := unwrapSingle(contents, contentsPath)
+		v.expect(boolean, boolPath, kindBool)
+		return "bool"
+
+	case "AbiString":
+		str, strPath := unwrapSingle(contents, contentsPath)
+		v.expect(str, strPath, kindString)
+		return "string"
+
+	case "AbiBytesDynamic":
+		v.checkByteString(contents, contentsPath)
+		return "bytes"
+
+	case "AbiBytes":
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 2, "size and bytes") {
+			return ""
+		}
+		size, ok := v.checkSize(contents.items[0], joinIndex(contentsPath, 0), 1, 32, 1)
+		if !ok {
+			return ""
+		}
+		bytesPath := joinIndex(contentsPath, 1)
+		if length := v.checkByteString(contents.items[1], bytesPath); length >= 0 && length != size {
+			v.report(contents.items[1], bytesPath, SeverityError, "bytes%d value has %d bytes", size, length)
+		}
+		return fmt.Sprintf("bytes%d", size)
+
+	case "AbiArrayDynamic":
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 2, "element type and values") {
+			return ""
+		}
+		elemType := v.checkType(contents.items[0], joinIndex(contentsPath, 0))
+		v.checkElements(contents.items[1], joinIndex(contentsPath, 1), elemType)
+		if elemType == "" {
+			return ""
+		}
+		return elemType + "[]"
+
+	case "AbiArray":
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 3, "length, element type and values") {
+			return ""
+		}
+		length, lengthOK := v.checkSize(contents.items[0], joinIndex(contentsPath, 0), 1, 1<<31, 1)
+		elemType := v.checkType(contents.items[1], joinIndex(contentsPath, 1))
+		valuesPath := joinIndex(contentsPath, 2)
+		v.checkElements(contents.items[2], valuesPath, elemType)
+		if lengthOK && contents.items[2].kind == kindArray && len(contents.items[2].items) != length {
+			v.report(contents.items[2], valuesPath, SeverityError, "array declares %d elements but has %d", length, len(contents.items[2].items))
+		}
+		if !lengthOK || elemType == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s[%d]", elemType, length)
+
+	case "AbiTuple":
+		if !v.expect(contents, contentsPath, kindArray) {
+			return ""
+		}
+		componentTypes := make([]string, 0, len(contents.items))
+		valid := true
+		for i, component := range contents.items {
+			componentType := v.checkValue(component, joinIndex(contentsPath, i))
+			valid = valid && componentType != ""
+			componentTypes = append(componentTypes, componentType)
+		}
+		if !valid {
+			return ""
+		}
+		return "(" + strings.Join(componentTypes, ",") + ")"
+	}
+	return ""
+}
+
+// checkElements checks the values of an array against its declared element type
+func (v *validator) checkElements(values *node, path, elemType string) {
+	if !v.expect(values, path, kindArray) {
+		return
+	}
+	for i, element := range values.items {
+		elementPath := joinIndex(path, i)
+		actual := v.checkValue(element, elementPath)
+		if actual != "" && elemType != "" && actual != elemType {
+			v.report(element, elementPath, SeverityError, "element of type %s in an array of %s", actual, elemType)
+		}
+	}
+}
+
+// checkType checks an ABI type descriptor and returns its Solidity type, or "" when it is invalid
+func (v *validator) checkType(typ *node, path string) string {
+	if !v.expect(typ, path, kindObject) {
+		return ""
+	}
+	tag, ok := v.checkTag(typ, path, []string{"AbiUIntType", "AbiIntType", "AbiAddressType", "AbiBoolType",
+		"AbiBytesType", "AbiBytesDynamicType", "AbiStringType", "AbiArrayDynamicType", "AbiArrayType", "AbiTupleType"})
+	if !ok {
+		return ""
+	}
+
+	contentsPath := joinField(path, "contents")
+	contents := typ.get("contents")
+
+	switch tag {
+	case "AbiAddressType", "AbiBoolType", "AbiBytesDynamicType", "AbiStringType":
+		v.checkFields(typ, path, []string{"tag"}, []string{"contents"})
+		return map[string]string{"AbiAddressType": "address", "AbiBoolType": "bool",
+			"AbiBytesDynamicType": "bytes", "AbiStringType": "string"}[tag]
+	}
+
+	if !v.checkFields(typ, path, []string{"tag", "contents"}, nil) {
+		return ""
+	}
+
+	switch tag {
+	case "AbiUIntType", "AbiIntType":
+		size, ok := v.checkSize(contents, contentsPath, 8, 256, 8)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%s%d", map[string]string{"AbiUIntType": "uint", "AbiIntType": "int"}[tag], size)
+	case "AbiBytesType":
+		size, ok := v.checkSize(contents, contentsPath, 1, 32, 1)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("bytes%d", size)
+	case "AbiArrayDynamicType":
+		elemType := v.checkType(contents, contentsPath)
+		if elemType == "" {
+			return ""
+		}
+		return elemType + "[]"
+	case "AbiArrayType":
+		if !v.expect(contents, contentsPath, kindArray) || !v.expectLength(contents, contentsPath, 2, "length and element type") {
+			return ""
+		}
+		length, lengthOK := v.checkSize(contents.items[0], joinIndex(contentsPath, 0), 1, 1<<31, 1)
+		elemType := v.checkType(contents.items[1], joinIndex(contentsPath, 1))
+		if !lengthOK || elemType == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s[%d]", elemType, length)
+	default: // AbiTupleType
+		if !v.expect(contents, contentsPath, kindArray) {
+			return ""
+		}
+		componentTypes := make([]string, 0, len(contents.items))
+		for i, component := range contents.items {
+			componentType := v.checkType(component, joinIndex(contentsPath, i))
+			if componentType == "" {
+				return ""
+			}
+			componentTypes = append(componentTypes, componentType)
+		}
+		return "(" + strings.Join(componentTypes, ",") + ")"
+	}
+}
+
+// checkSize checks an integer size between min and max that is a multiple of step
+func (v *validator) checkSize(n *node, path string, min, max, step int) (int, bool) {
+	if !v.expect(n, path, kindNumber) {
+		return 0, false
+	}
+	size, err := strconv.Atoi(n.number.String())
+	if err != nil || size < min || size > max || size%step != 0 {
+		if step > 1 {
+			v.report(n, path, SeverityError, "invalid size %s (expected a multiple of %d between %d and %d)", n.number, step, min, max)
+		} else {
+			v.report(n, path, SeverityError, "invalid size %s (expected %d to %d)", n.number, min, max)
+		}
+		return 0, false
+	}
+	return size, true
+}
+
+// checkInteger checks a decimal integer, given as a string or a number, against the range of its type
+func (v *validator) checkInteger(n *node, path string, bits int, signed bool) bool {
+	var digits string
+	switch n.kind {
+	case kindString:
+		digits = n.str
+	case kindNumber:
+		digits = n.number.String()
+	default:
+		v.report(n, path, SeverityError, "expected a decimal integer, found %s", n.kind)
+		return false
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		v.report(n, path, SeverityError, "%q is not a decimal integer", digits)
+		return false
+	}
+
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if value.Cmp(min) < 0 || value.Cmp(max) >= 0 {
+		typeName := "uint"
+		if signed {
+			typeName = "int"
+		}
+		v.report(n, path, SeverityError, "%s is out of range for %s%d", value, typeName, bits)
+		return false
+	}
+	return true
+}
+
+// unwrapSingle returns the element of a single-element array, for values that runes
+// accepts either bare or wrapped in an array
+func unwrapSingle(n *node, path string) (*node, string) {
+	if n.kind == kindArray && len(n.items) == 1 {
+		return n.items[0], joinIndex(path, 0)
+	}
+	return n, path
+}
+
+// isIdentifier reports whether a name is a valid Solidity identifier
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		letter := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// nodeKind is the JSON type of a node
+type nodeKind int
+
+const (
+	kindNull nodeKind = iota
+	kindBool
+	kindNumber
+	kindString
+	kindArray
+	kindObject
+)
+
+// String returns the JSON name of the kind, as used in diagnostics
+func (k nodeKind) String() string {
+	return [...]string{"null", "boolean", "number", "string", "array", "object"}[k]
+}
+
+// node is a decoded JSON value that remembers where it starts in the source
+type node struct {
+	kind   nodeKind
+	offset int // Byte offset of the first character of the value
+
+	boolean bool
+	number  json.Number
+	str     string
+	items   []*node // Array elements
+	fields  []field // Object members in source order
+}
+
+// field is a member of a JSON object
+type field struct {
+	key   string
+	value *node
+}
+
+// get returns the value of an object member, matching keys case-insensitively like encoding/json
+func (n *node) get(key string) *node {
+	for _, f := range n.fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	for _, f := range n.fields {
+		if bytes.EqualFold([]byte(f.key), []byte(key)) {
+			return f.value
+		}
+	}
+	return nil
+}
+
+// syntaxError is malformed JSON at a byte offset
+type syntaxError struct {
+	offset  int
+	message string
+}
+
+func (e *syntaxError) Error() string {
+	return e.message
+}
+
+// jsonParser builds a node tree with source offsets from the tokens of a json.Decoder
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// parseJSON decodes a single JSON document into a node tree.
+// Malformed JSON is returned as a *syntaxError carrying the offset of the problem.
+func parseJSON(data []byte) (*node, error) {
+	p := &jsonParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+
+	root, err := p.parseValue()
+	if err != nil {
+		var ownErr *syntaxError
+		if errors.As(err, &ownErr) {
+			return nil, ownErr
+		}
+		var jsonErr *json.SyntaxError
+		if errors.As(err, &jsonErr) && jsonErr.Offset > 0 {
+			// The decoder reports the offset just past the offending character
+			return nil, &syntaxError{offset: int(jsonErr.Offset) - 1, message: jsonErr.Error()}
+		}
+		return nil, &syntaxError{offset: p.nextOffset(), message: err.Error()}
+	}
+
+	// Anything but whitespace after the document is an error
+	if _, err := p.dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, &syntaxError{offset: p.nextOffset(), message: "unexpected data after the top-level value"}
+	}
+	return root, nil
+}
+
+// parseValue decodes the next value and its children
+func (p *jsonParser) parseValue() (*node, error) {
+	start := p.nextOffset()
+	token, err := p.dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &syntaxError{offset: len(p.data), message: "unexpected end of JSON input"}
+		}
+		return nil, err
+	}
+
+	n := &node{offset: start}
+	switch value := token.(type) {
+	case nil:
+		n.kind = kindNull
+	case bool:
+		n.kind, n.boolean = kindBool, value
+	case json.Number:
+		n.kind, n.number = kindNumber, value
+	case string:
+		n.kind, n.str = kindString, value
+	case json.Delim:
+		switch value {
+		case '[':
+			n.kind = kindArray
+			for p.dec.More() {
+				item, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, item)
+			}
+		case '{':
+			n.kind = kindObject
+			for p.dec.More() {
+				key, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				n.fields = append(n.fields, field{key: key.(string), value: value})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q", value)
+		}
+
+		// Consume the closing delimiter
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// nextOffset returns the offset of the next token, skipping whitespace and separators
+func (p *jsonParser) nextOffset() int {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// position converts a byte offset to a 1-based line and column
+func position(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = offset - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,241 @@
+// Package validate checks reproducer files against the schema of their format and
+// reports every problem with its JSON path and source position.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/Enigma-Dark/runes/internal/parser"
+)
+
+// Severities of a diagnostic
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a single problem found in a reproducer
+type Diagnostic struct {
+	File     string `json:"file"`
+	Path     string `json:"path"` // JSON path of the offending value, e.g. [3].call.contents[1][0].contents
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats the diagnostic as file:line:column: severity: path: message
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	if d.Path == "" {
+		return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", location, d.Severity, d.Path, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a reproducer and returns its problems sorted by position.
+// Echidna reproducers are checked against the full transaction schema, other
+// formats are checked by parsing them.
+func Validate(file string, data []byte) []Diagnostic {
+	v := &validator{file: file, data: data}
+
+	if format := parser.DetectFormat(data); format != parser.FormatEchidna {
+		if _, err := parser.ParseData(data); err != nil {
+			v.report(nil, "", SeverityError, "invalid %s reproducer: %v", format, err)
+		}
+		return v.diagnostics
+	}
+
+	root, err := parseJSON(data)
+	if err != nil {
+		offset := len(data)
+		var syntaxErr *syntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.offset
+		}
+		v.reportAt(offset, "", SeverityError, "invalid JSON: %v", err)
+		return v.diagnostics
+	}
+
+	v.checkReproducer(root)
+
+	// The schema should catch everything the parser rejects; report anything it missed
+	if !HasErrors(v.diagnostics) {
+		if _, err := parser.ParseData(data); err != nil {
+			v.report(root, "", SeverityError, "%v", err)
+		}
+	}
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.diagnostics
+}
+
+// validator collects the diagnostics of one file
+type validator struct {
+	file        string
+	data        []byte
+	diagnostics []Diagnostic
+}
+
+// report records a problem with a node (nil for the whole file)
+func (v *validator) report(n *node, path, severity, format string, args ...interface{}) {
+	if n == nil {
+		v.diagnostics = append(v.diagnostics, Diagnostic{
+			File:     v.file,
+			Path:     path,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+		return
+	}
+	v.reportAt(n.offset, path, severity, format, args...)
+}
+
+// reportAt records a problem at a byte offset
+func (v *validator) reportAt(offset int, path, severity, format string, args ...interface{}) {
+	line, column := position(v.data, offset)
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		File:     v.file,
+		Path:     path,
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// expect reports a node of the wrong JSON type and returns whether the type matched
+func (v *validator) expect(n *node, path string, kind nodeKind) bool {
+	if n.kind != kind {
+		v.report(n, path, SeverityError, "expected %s, found %s", kind, n.kind)
+		return false
+	}
+	return true
+}
+
+// expectLength reports an array with the wrong number of elements
+func (v *validator) expectLength(n *node, path string, length int, description string) bool {
+	if len(n.items) != length {
+		v.report(n, path, SeverityError, "expected %d elements (%s), found %d", length, description, len(n.items))
+		return false
+	}
+	return true
+}
+
+// checkFields reports missing required members and unknown members of an object.
+// It returns false when a required member is missing.
+func (v *validator) checkFields(n *node, path string, required, optional []string) bool {
+	complete := true
+	for _, key := range required {
+		if n.get(key) == nil {
+			v.report(n, path, SeverityError, "missing field %q", key)
+			complete = false
+		}
+	}
+
+	known := append(append([]string(nil), required...), optional...)
+	for _, f := range n.fields {
+		matched := false
+		for _, key := range known {
+			if strings.EqualFold(f.key, key) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(f.value, joinField(path, f.key), SeverityWarning, "unknown field %q", f.key)
+		}
+	}
+	return complete
+}
+
+// joinField appends an object member to a JSON path
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// joinIndex appends an array index to a JSON path
+func joinIndex(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// checkHexQuantity checks a 0x-prefixed hex number of at most bits bits
+func (v *validator) checkHexQuantity(n *node, path string, bits int) {
+	if !v.expect(n, path, kindString) {
+		return
+	}
+	digits, ok := strings.CutPrefix(strings.ToLower(n.str), "0x")
+	if !ok {
+		v.report(n, path, SeverityError, "hex number %q must start with 0x", n.str)
+		return
+	}
+	if digits == "" {
+		return
+	}
+	value, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		v.report(n, path, SeverityError, "%q is not a hex number", n.str)
+		return
+	}
+	if value.BitLen() > bits {
+		v.report(n, path, SeverityError, "%q does not fit in %d bits", n.str, bits)
+	}
+}
+
+// checkAddress checks a 0x-prefixed address of at most 20 bytes
+func (v *validator) checkAddress(n *node, path string) {
+	if !v.expect(n, path, kindString) {
+		return
+	}
+	digits, ok := strings.CutPrefix(strings.ToLower(n.str), "0x")
+	if !ok || digits == "" || len(digits) > 40 || strings.Trim(digits, "0123456789abcdef") != "" {
+		v.report(n, path, SeverityError, "%q is not an address", n.str)
+	}
+}
+
+// checkByteString checks a byte string: 0x-prefixed hex or one character per byte.
+// It returns the number of bytes, or -1 when the string is invalid.
+func (v *validator) checkByteString(n *node, path string) int {
+	if !v.expect(n, path, kindString) {
+		return -1
+	}
+	if digits, ok := strings.CutPrefix(n.str, "0x"); ok && len(digits)%2 == 0 && strings.Trim(strings.ToLower(digits), "0123456789abcdef") == "" {
+		return len(digits) / 2
+	}
+
+	length := 0
+	for _, r := range n.str {
+		if r > 0xFF {
+			v.report(n, path, SeverityError, "byte string contains the non-byte character %q", r)
+			return -1
+		}
+		length++
+	}
+	return length
+}
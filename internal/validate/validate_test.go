@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_Malformed(t *testing.T) {
+	data, err := os.ReadFile("testdata/malformed.txt")
+	require.NoError(t, err)
+
+	diagnostics := Validate("malformed.txt", data)
+	require.True(t, HasErrors(diagnostics))
+
+	byPath := make(map[string]Diagnostic)
+	for _, d := range diagnostics {
+		byPath[d.Path] = d
+	}
+
+	overflow := byPath["[0].call.contents[1][0].contents[1]"]
+	assert.Equal(t, "300 is out of range for uint8", overflow.Message)
+	assert.Equal(t, 8, overflow.Line)
+	assert.Equal(t, 46, overflow.Column)
+	assert.Equal(t, "malformed.txt:8:46: error: [0].call.contents[1][0].contents[1]: 300 is out of range for uint8", overflow.String())
+
+	assert.Contains(t, byPath["[0].call.contents[1][1].contents[1][0]"].Message, "element of type bool in an array of uint256")
+	assert.Contains(t, byPath["[0].call.contents[1][2].contents[1]"].Message, "bytes4 value has 5 bytes")
+	assert.Contains(t, byPath["[0].call.contents[1][3].tag"].Message, `unknown tag "AbiFixed"`)
+	assert.Contains(t, byPath["[0].dst"].Message, "is not an address")
+	assert.Contains(t, byPath["[0].delay"].Message, "expected 2 elements")
+	assert.Contains(t, byPath["[1]"].Message, `missing field "value"`)
+	assert.Contains(t, byPath["[1].delay[1]"].Message, "is not a hex number")
+
+	unknown := byPath["[0].comment"]
+	assert.Equal(t, SeverityWarning, unknown.Severity)
+
+	// Diagnostics are sorted by position
+	for i := 1; i < len(diagnostics); i++ {
+		assert.LessOrEqual(t, diagnostics[i-1].Line, diagnostics[i].Line)
+	}
+}
+
+func TestValidate_ValidFiles(t *testing.T) {
+	for _, path := range []string{
+		"../parser/testdata/valid_reproducer.json",
+		"../parser/testdata/medusa_sequence.json",
+		"../parser/testdata/foundry_sequence.txt",
+	} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Empty(t, Validate(path, data), path)
+	}
+}
+
+func TestValidate_SyntaxError(t *testing.T) {
+	diagnostics := Validate("broken.txt", []byte("[\n  {\"call\": {\"tag\": \"SolCall\",,}}\n]"))
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, 2, diagnostics[0].Line)
+	assert.Equal(t, 30, diagnostics[0].Column)
+	assert.Contains(t, diagnostics[0].Message, "invalid JSON")
+}
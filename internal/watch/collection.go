@@ -0,0 +1,113 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Enigma-Dark/runes/internal/replay"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// Collection accumulates the replay groups of a watched directory, one per reproducer file.
+// Test names are assigned once and stay stable while the file is rewritten, and call
+// sequences already present under another file are not added twice.
+type Collection struct {
+	// Property is the property broken by every reproducer, instead of inferring it (optional)
+	Property string
+
+	// Campaign is the campaign log the broken properties are looked up in (optional)
+	Campaign *replay.Campaign
+
+	groups       []types.ReplayGroup
+	fingerprints []string
+	byPath       map[string]int
+	namer        *replay.Namer
+}
+
+// NewCollection creates an empty collection
+func NewCollection() *Collection {
+	return &Collection{
+		byPath: make(map[string]int),
+		namer:  replay.NewNamer(""),
+	}
+}
+
+// Reserve keeps a test name from being assigned, e.g. one already declared by the
+// contract the tests are merged into
+func (c *Collection) Reserve(name string) {
+	c.namer.Reserve(name)
+}
+
+// Update stores the calls parsed from path and reports whether the collection changed
+func (c *Collection) Update(path string, calls []types.ParsedCall) (bool, error) {
+	fingerprint, err := sequenceFingerprint(calls)
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range c.fingerprints {
+		if existing == fingerprint {
+			// Unchanged file, or the same sequence already replayed under another file
+			return false, nil
+		}
+	}
+
+	if index, ok := c.byPath[path]; ok {
+		c.groups[index].Calls = calls
+		c.fingerprints[index] = fingerprint
+		return true, nil
+	}
+
+	property := c.Property
+	if property == "" {
+		property = replay.InferProperty(path, calls, c.Campaign)
+	}
+	name := c.namer.Name(property, calls)
+
+	c.byPath[path] = len(c.groups)
+	c.groups = append(c.groups, types.ReplayGroup{
+		TestName: name,
+		Calls:    calls,
+		FileName: path,
+		Property: property,
+	})
+	c.fingerprints = append(c.fingerprints, fingerprint)
+	return true, nil
+}
+
+// Groups returns the replay groups in the order their files first appeared
+func (c *Collection) Groups() []types.ReplayGroup {
+	return append([]types.ReplayGroup(nil), c.groups...)
+}
+
+// Group returns the replay group of path, if any
+func (c *Collection) Group(path string) (types.ReplayGroup, bool) {
+	index, ok := c.byPath[path]
+	if !ok {
+		return types.ReplayGroup{}, false
+	}
+	return c.groups[index], true
+}
+
+// Len returns the number of replay groups
+func (c *Collection) Len() int {
+	return len(c.groups)
+}
+
+// TestName returns the test function name generated for path, if any
+func (c *Collection) TestName(path string) (string, bool) {
+	index, ok := c.byPath[path]
+	if !ok {
+		return "", false
+	}
+	return c.groups[index].TestName, true
+}
+
+// sequenceFingerprint identifies a call sequence by its full contents
+func sequenceFingerprint(calls []types.ParsedCall) (string, error) {
+	data, err := json.Marshal(calls)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint call sequence: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,163 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// sequence builds a call sequence from function names
+func sequence(names ...string) []types.ParsedCall {
+	var calls []types.ParsedCall
+	for _, name := range names {
+		calls = append(calls, types.ParsedCall{FunctionName: name})
+	}
+	return calls
+}
+
+func TestCollection_Update(t *testing.T) {
+	collection := NewCollection()
+
+	changed, err := collection.Update("a.txt", sequence("deposit", "withdraw"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// Rewriting a file with the same sequence is not a change
+	changed, err = collection.Update("a.txt", sequence("deposit", "withdraw"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// The same sequence under another file is not added twice
+	changed, err = collection.Update("b.txt", sequence("deposit", "withdraw"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// A shrunk file replaces its previous sequence and keeps its test name
+	changed, err = collection.Update("a.txt", sequence("withdraw"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// Reserved names, e.g. tests already in the contract, are not reused
+	collection.Reserve("test_replay_withdraw_2")
+	changed, err = collection.Update("c.txt", sequence("borrow", "withdraw"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	groups := collection.Groups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "test_replay_withdraw", groups[0].TestName)
+	assert.Equal(t, sequence("withdraw"), groups[0].Calls)
+	assert.Equal(t, "test_replay_withdraw_3", groups[1].TestName)
+	assert.Equal(t, "c.txt", groups[1].FileName)
+
+	group, ok := collection.Group("c.txt")
+	require.True(t, ok)
+	assert.Equal(t, groups[1], group)
+}
+
+func TestCollection_Property(t *testing.T) {
+	collection := NewCollection()
+
+	// Inferred from the file name
+	_, err := collection.Update("invariant_solvency.txt", sequence("deposit"))
+	require.NoError(t, err)
+	group, _ := collection.Group("invariant_solvency.txt")
+	assert.Equal(t, "invariant_solvency", group.Property)
+
+	// Set for every reproducer
+	collection.Property = "echidna_balance"
+	_, err = collection.Update("a.txt", sequence("withdraw"))
+	require.NoError(t, err)
+	group, _ = collection.Group("a.txt")
+	assert.Equal(t, "echidna_balance", group.Property)
+	assert.Equal(t, "test_replay_echidna_balance_1", group.TestName)
+}
+
+func TestWatcher_DebouncesBursts(t *testing.T) {
+	dir := t.TempDir()
+	watcher := &Watcher{Dir: dir, Debounce: 200 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	changes := make(chan []string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx, func(paths []string) { changes <- paths })
+	}()
+
+	// Give the watcher time to register the directory
+	time.Sleep(100 * time.Millisecond)
+
+	path := filepath.Join(dir, "reproducer.txt")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("ignored"), 0644))
+
+	select {
+	case paths := <-changes:
+		assert.Equal(t, []string{path}, paths)
+	case <-ctx.Done():
+		t.Fatal("no change reported")
+	}
+
+	// The burst is reported once
+	select {
+	case paths := <-changes:
+		t.Fatalf("unexpected second change: %v", paths)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatcher_Renames(t *testing.T) {
+	dir := t.TempDir()
+	watcher := &Watcher{Dir: dir, Debounce: 200 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	changes := make(chan []string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx, func(paths []string) { changes <- paths })
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// Written to a temporary file, then moved into place
+	temporary := filepath.Join(dir, ".reproducer.tmp")
+	first := filepath.Join(dir, "first.txt")
+	require.NoError(t, os.WriteFile(temporary, []byte("[]"), 0644))
+	require.NoError(t, os.Rename(temporary, first))
+
+	select {
+	case paths := <-changes:
+		assert.Equal(t, []string{first}, paths)
+	case <-ctx.Done():
+		t.Fatal("no change reported")
+	}
+
+	// A renamed reproducer is reported under its new name only
+	second := filepath.Join(dir, "second.txt")
+	require.NoError(t, os.Rename(first, second))
+
+	select {
+	case paths := <-changes:
+		assert.Equal(t, []string{second}, paths)
+	case <-ctx.Done():
+		t.Fatal("no change reported")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
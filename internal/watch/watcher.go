@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/Enigma-Dark/runes/internal/files"
+)
+
+// DefaultDebounce is the quiet period after the last write before changed files are reported
+const DefaultDebounce = 2 * time.Second
+
+// Watcher reports reproducer files that are created, rewritten or renamed in a directory
+type Watcher struct {
+	Dir string
+
+	// Debounce is the quiet period that ends a burst of writes (defaults to DefaultDebounce).
+	// A shrinking run rewrites the same file many times; it is reported once when it settles.
+	Debounce time.Duration
+
+	// OnError is called with errors reported by the file system watcher (optional)
+	OnError func(err error)
+}
+
+// Run watches the directory until ctx is cancelled, calling onChange with the
+// sorted paths of all reproducer files changed during each burst. Files written to a
+// temporary name and renamed into place are reported under their final name; paths
+// that no longer exist when the burst settles are left out.
+func (w *Watcher) Run(ctx context.Context, onChange func(paths []string)) error {
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(w.Dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.Dir, err)
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			if !files.IsReproducerFile(filepath.Base(event.Name)) {
+				continue
+			}
+			pending[event.Name] = true
+
+			// Every new event restarts the quiet period
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				if _, err := os.Stat(path); err == nil {
+					paths = append(paths, path)
+				}
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			if len(paths) > 0 {
+				onChange(paths)
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package runes converts fuzzer reproducers (Echidna, Medusa) into Foundry replay tests
+// and Medusa call sequences.
+//
+// It is the stable, embeddable API of the runes CLI: parsing reads from an io.Reader,
+// generation writes to an io.Writer, and nothing is printed. Additional input formats
+// and templates can be registered at startup and are then also used by detection and
+// template lookup.
+//
+//	calls, err := runes.Parse(file)
+//	...
+//	err = runes.Generate(os.Stdout, runes.Options{ContractName: "ReplayTest"},
+//		[]runes.ReplayGroup{{TestName: "test_replay", Calls: calls}})
+package runes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Enigma-Dark/runes/internal/abi"
+	"github.com/Enigma-Dark/runes/internal/encoder"
+	"github.com/Enigma-Dark/runes/internal/generator"
+	"github.com/Enigma-Dark/runes/internal/parser"
+	"github.com/Enigma-Dark/runes/internal/replay"
+	"github.com/Enigma-Dark/runes/internal/templates"
+	"github.com/Enigma-Dark/runes/internal/types"
+)
+
+// ParsedCall is a single transaction of a reproducer: a function call, a delay, or both
+type ParsedCall = types.ParsedCall
+
+// ParsedParam is a typed call argument; arrays and tuples carry their Elements
+type ParsedParam = types.ParsedParam
+
+// ReplayGroup is the call sequence replayed by one test function
+type ReplayGroup = types.ReplayGroup
+
+// ABI is the ABI of the contract targeted by the reproducers
+type ABI = abi.ABI
+
+// DefaultTemplate is the template used when Options.Template is empty
+const DefaultTemplate = generator.DefaultTemplate
+
+// Options controls test generation
+type Options struct {
+	// ContractName is the name of the generated test contract
+	ContractName string
+
+	// Template is a builtin or registered template name, or the path of a .tmpl file
+	// (defaults to DefaultTemplate)
+	Template string
+
+	// Actors maps sender addresses to actor constant names, overriding Echidna's
+	// default senders (optional)
+	Actors map[string]string
+
+	// ABI of the target contract, used to resolve overloads and struct names (optional)
+	ABI *ABI
+}
+
+// Parse reads a reproducer, detecting its format, and returns its calls
+func Parse(r io.Reader) ([]ParsedCall, error) {
+	return parser.ParseReproducer(r)
+}
+
+// Generate writes a Foundry test contract replaying the groups to w.
+// Groups without a TestName are named after the property their file name mentions,
+// or their last function call, and made unique.
+func Generate(w io.Writer, options Options, groups []ReplayGroup) error {
+	actors, err := generator.NewActorTable(options.Actors)
+	if err != nil {
+		return fmt.Errorf("invalid actors: %w", err)
+	}
+
+	// Generated names must not collide with the names given by the caller
+	namer := replay.NewNamer("")
+	for _, group := range groups {
+		if group.TestName != "" {
+			namer.Reserve(group.TestName)
+		}
+	}
+	named := make([]ReplayGroup, len(groups))
+	for i, group := range groups {
+		if group.TestName == "" {
+			property := group.Property
+			if property == "" {
+				property = replay.InferProperty(group.FileName, group.Calls, nil)
+			}
+			group.TestName = namer.Name(property, group.Calls)
+		}
+		named[i] = group
+	}
+
+	return generator.Render(w, generator.GenerateConfig{
+		ContractName: options.ContractName,
+		ReplayGroups: named,
+		Template:     options.Template,
+		Actors:       actors,
+		ABI:          options.ABI,
+	})
+}
+
+// MedusaOptions controls how calls are written as a Medusa call sequence
+type MedusaOptions struct {
+	// ABI of the target contract, providing the field names Medusa keys tuple arguments
+	// by; calls with tuple arguments otherwise only carry their calldata (optional)
+	ABI *ABI
+
+	// Target is the address every call is sent to, such as DefaultMedusaTarget
+	// (defaults to the calls' own targets)
+	Target string
+}
+
+// DefaultMedusaTarget is the address Medusa deploys the first target contract at
+const DefaultMedusaTarget = encoder.DefaultMedusaTarget
+
+// WriteMedusa writes the calls to w as a Medusa call sequence that can seed a Medusa corpus
+func WriteMedusa(w io.Writer, calls []ParsedCall, options MedusaOptions) error {
+	data, err := encoder.MarshalMedusa(calls, encoder.MedusaOptions{ABI: options.ABI, Target: options.Target})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// RegisterFormat adds an input format to detection. Registered formats are tried
+// after the builtin Medusa and Foundry formats and before the Echidna fallback, which
+// accepts any input. detect should be cheap and only report true for its own format.
+func RegisterFormat(name string, detect func(data []byte) bool, parse func(data []byte) ([]ParsedCall, error)) error {
+	return parser.RegisterFormat(parser.Format{
+		Name:   name,
+		Detect: detect,
+		Parse:  parse,
+	})
+}
+
+// Formats returns the names of the known input formats in detection order
+func Formats() []string {
+	return parser.FormatNames()
+}
+
+// RegisterTemplate adds a text/template that Options.Template can select by name.
+// It takes precedence over a builtin template of the same name.
+func RegisterTemplate(name, content string) error {
+	return templates.Register(name, content)
+}
+
+// Templates returns the names of the builtin and registered templates
+func Templates() ([]string, error) {
+	return generator.ListAvailableTemplates()
+}
+
+// ParseABI parses a JSON ABI array
+func ParseABI(data []byte) (*ABI, error) {
+	return abi.Parse(data)
+}
+
+// LoadABI loads the ABI of a contract from a Foundry out directory. name is the
+// contract name, or "Source.sol:Name" when several sources declare it.
+func LoadABI(artifactsDir, name string) (*ABI, error) {
+	artifacts, err := abi.LoadArtifacts(artifactsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := artifacts.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return artifact.ABI, nil
+}
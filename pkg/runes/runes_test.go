@@ -0,0 +1,101 @@
+package runes_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Enigma-Dark/runes/pkg/runes"
+)
+
+func TestParseAndGenerate(t *testing.T) {
+	file, err := os.Open("../../internal/parser/testdata/valid_reproducer.json")
+	require.NoError(t, err)
+	defer file.Close()
+
+	calls, err := runes.Parse(file)
+	require.NoError(t, err)
+	require.NotEmpty(t, calls)
+
+	var out bytes.Buffer
+	err = runes.Generate(&out, runes.Options{ContractName: "LibraryReplay", Template: "basic"},
+		[]runes.ReplayGroup{{Calls: calls}})
+	require.NoError(t, err)
+
+	generated := out.String()
+	assert.Contains(t, generated, "contract LibraryReplay")
+	assert.Contains(t, generated, "function test_replay_"+calls[len(calls)-1].FunctionName+"()")
+}
+
+func TestRegisterFormat(t *testing.T) {
+	// A line based format: one function name per line
+	err := runes.RegisterFormat("lines",
+		func(data []byte) bool { return bytes.HasPrefix(data, []byte("#lines")) },
+		func(data []byte) ([]runes.ParsedCall, error) {
+			var calls []runes.ParsedCall
+			for _, line := range strings.Split(string(data), "\n")[1:] {
+				if line = strings.TrimSpace(line); line != "" {
+					calls = append(calls, runes.ParsedCall{FunctionName: line})
+				}
+			}
+			return calls, nil
+		})
+	require.NoError(t, err)
+
+	assert.Error(t, runes.RegisterFormat("lines", func([]byte) bool { return false }, nil))
+
+	// Registered formats are detected after the builtin ones, before the Echidna fallback
+	formats := runes.Formats()
+	assert.Equal(t, []string{"lines", "echidna"}, formats[len(formats)-2:])
+
+	calls, err := runes.Parse(strings.NewReader("#lines\ndeposit\nwithdraw\n"))
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "withdraw", calls[1].FunctionName)
+}
+
+func TestRegisterTemplate(t *testing.T) {
+	err := runes.RegisterTemplate("names", `{{range .ReplayGroups}}{{.TestName}}
+{{end}}`)
+	require.NoError(t, err)
+
+	names, err := runes.Templates()
+	require.NoError(t, err)
+	assert.Contains(t, names, "names")
+
+	var out bytes.Buffer
+	err = runes.Generate(&out, runes.Options{Template: "names"}, []runes.ReplayGroup{
+		{TestName: "test_a", Calls: []runes.ParsedCall{{FunctionName: "a"}}},
+		{TestName: "test_b", Calls: []runes.ParsedCall{{FunctionName: "b"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test_a\ntest_b\n", out.String())
+
+	assert.Error(t, runes.RegisterTemplate("broken", "{{range}}"))
+}
+
+func ExampleGenerate() {
+	calls := []runes.ParsedCall{{
+		FunctionName: "deposit",
+		Parameters:   []runes.ParsedParam{{Type: "uint256", Value: "100"}},
+		Src:          "0x0000000000000000000000000000000000010000",
+	}}
+
+	var out bytes.Buffer
+	err := runes.Generate(&out, runes.Options{ContractName: "Replay"}, []runes.ReplayGroup{{TestName: "test_deposit", Calls: calls}})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.Contains(line, "Tester.") {
+			fmt.Println(strings.TrimSpace(line))
+		}
+	}
+	// Output: Tester.deposit(100);
+}